@@ -7,6 +7,7 @@ import (
 
 // APIKeysService provides methods for managing api keys.
 // It uses a Client to communicate with the api-keys API endpoints.
+// Create and Revoke send an idempotency key so that retries are safe.
 type APIKeysService struct {
 	client *Client
 }
@@ -14,7 +15,8 @@ type APIKeysService struct {
 // List retrieves all API keys for a given project and environment.
 // It takes a context, projectID, and env as parameters and returns a slice of APIKey objects.
 // Returns an error if the request fails.
-func (s *APIKeysService) List(ctx context.Context, projectID, env string) ([]APIKey, error) {
+func (s *APIKeysService) List(ctx context.Context, projectID, env string, opts ...RequestOption) ([]APIKey, error) {
+	ctx = withRequestOptions(ctx, opts)
 	path := fmt.Sprintf("/api/projects/%s/api-keys?environment=%s", projectID, env)
 
 	type response struct {
@@ -30,7 +32,9 @@ func (s *APIKeysService) List(ctx context.Context, projectID, env string) ([]API
 
 // Create creates a new API key for the specified project.
 // It takes a context, project ID, and API key parameters, then returns the created API key or an error.
-func (s *APIKeysService) Create(ctx context.Context, projectID string, params CreateAPIKeyParams) (*APIKey, error) {
+func (s *APIKeysService) Create(ctx context.Context, projectID string, params CreateAPIKeyParams, opts ...RequestOption) (*APIKey, error) {
+	ctx = withRequestOptions(ctx, opts)
+	ctx = withIdempotencyKey(ctx)
 	path := fmt.Sprintf("/api/projects/%s/api-keys", projectID)
 	return doRequest[CreateAPIKeyParams, APIKey](s.client, ctx, "POST", path, &params)
 }
@@ -38,7 +42,9 @@ func (s *APIKeysService) Create(ctx context.Context, projectID string, params Cr
 // Revoke deletes an API key
 // It sends a DELETE request to the api-keyAPI endpoint with the given projectID and key.
 // Returns an error if the request fails.
-func (s *APIKeysService) Revoke(ctx context.Context, projectID, keyID string) error {
+func (s *APIKeysService) Revoke(ctx context.Context, projectID, keyID string, opts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, opts)
+	ctx = withIdempotencyKey(ctx)
 	path := fmt.Sprintf("/api/projects/%s/api-keys/%s", projectID, keyID)
 	_, err := doRequest[any, any](s.client, ctx, "DELETE", path, nil)
 	return err
@@ -46,7 +52,8 @@ func (s *APIKeysService) Revoke(ctx context.Context, projectID, keyID string) er
 
 // GetStats retrieve usage statistics for a specific API key.
 // It takes a context, project ID, and API key parameters, then returns the created API key or an error.
-func (s *APIKeysService) GetStats(ctx context.Context, projectID, keyID string) (*KeyUsageStats, error) {
+func (s *APIKeysService) GetStats(ctx context.Context, projectID, keyID string, opts ...RequestOption) (*KeyUsageStats, error) {
+	ctx = withRequestOptions(ctx, opts)
 	path := fmt.Sprintf("/api/projects/%s/api-keys/%s/stats", projectID, keyID)
 	return doRequest[any, KeyUsageStats](s.client, ctx, "GET", path, nil)
 }
@@ -0,0 +1,278 @@
+package cencori
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// BatchesService runs many chat requests as one asynchronous job.
+// It uses a Client to communicate with the batches API endpoints.
+type BatchesService struct {
+	client *Client
+}
+
+// ErrBatchUnavailable is returned when the server does not support batch jobs.
+var ErrBatchUnavailable = errors.New("cencori: batch endpoint unavailable")
+
+// ReadBatchRequests parses a JSONL batch input: one BatchRequest per line.
+// Requests without a custom ID are numbered by line ("request-1", ...).
+func ReadBatchRequests(r io.Reader) ([]BatchRequest, error) {
+	var requests []BatchRequest
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxResponseSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var req BatchRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			return nil, fmt.Errorf("batch input line %d: %w", line, err)
+		}
+		if req.CustomID == "" {
+			req.CustomID = fmt.Sprintf("request-%d", line)
+		}
+		requests = append(requests, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read batch input: %w", err)
+	}
+	return requests, nil
+}
+
+// WriteBatchResults writes results as JSONL, one BatchResult per line.
+func WriteBatchResults(w io.Writer, results []BatchResult) error {
+	enc := json.NewEncoder(w)
+	for _, r := range results {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("write batch results: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateBatch(requests []BatchRequest) error {
+	seen := make(map[string]bool, len(requests))
+	for _, r := range requests {
+		if r.CustomID == "" {
+			return errors.New("cencori: batch request without custom_id")
+		}
+		if seen[r.CustomID] {
+			return fmt.Errorf("cencori: duplicate batch custom_id %q", r.CustomID)
+		}
+		seen[r.CustomID] = true
+	}
+	return nil
+}
+
+// Create submits a batch job. It returns ErrBatchUnavailable if the server
+// has no batch endpoint.
+//
+// The requests are sent as given: chat middleware, such as a Redactor, and
+// the WithModeration check do not run on server-side batches. Redact or
+// screen the requests before submitting them, or use RunLocal.
+func (s *BatchesService) Create(ctx context.Context, params CreateBatchParams, opts ...RequestOption) (*Batch, error) {
+	ctx = withRequestOptions(ctx, opts)
+	if err := validateBatch(params.Requests); err != nil {
+		return nil, err
+	}
+	params.Requests = slices.Clone(params.Requests)
+	for i := range params.Requests {
+		params.Requests[i].Params.Stream = false
+	}
+
+	batch, err := doRequest[CreateBatchParams, Batch](s.client, ctx, "POST", "/api/v1/batches", &params)
+	if isUnavailable(err) {
+		return nil, fmt.Errorf("%w: %w", ErrBatchUnavailable, err)
+	}
+	return batch, err
+}
+
+// Get retrieves the current status of a batch job.
+func (s *BatchesService) Get(ctx context.Context, batchID string, opts ...RequestOption) (*Batch, error) {
+	ctx = withRequestOptions(ctx, opts)
+	path := fmt.Sprintf("/api/v1/batches/%s", batchID)
+	return doRequest[any, Batch](s.client, ctx, "GET", path, nil)
+}
+
+// Cancel stops a batch job. Requests that already finished keep their results.
+func (s *BatchesService) Cancel(ctx context.Context, batchID string, opts ...RequestOption) (*Batch, error) {
+	ctx = withRequestOptions(ctx, opts)
+	path := fmt.Sprintf("/api/v1/batches/%s/cancel", batchID)
+	return doRequest[any, Batch](s.client, ctx, "POST", path, nil)
+}
+
+// Wait polls a batch job every interval until it reaches a terminal status
+// or ctx is done.
+func (s *BatchesService) Wait(ctx context.Context, batchID string, interval time.Duration, opts ...RequestOption) (*Batch, error) {
+	ctx = withRequestOptions(ctx, opts)
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		batch, err := s.Get(ctx, batchID)
+		if err != nil {
+			return nil, err
+		}
+		if batch.Status.Done() {
+			return batch, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Results downloads the output of a finished batch job.
+func (s *BatchesService) Results(ctx context.Context, batchID string, opts ...RequestOption) ([]BatchResult, error) {
+	ctx = withRequestOptions(ctx, opts)
+	path := fmt.Sprintf("/api/v1/batches/%s/results", batchID)
+
+	results, err := doRequestWith[any](s.client, ctx, "GET", path, nil, decodeBatchResults)
+	if err != nil {
+		return nil, err
+	}
+	return *results, nil
+}
+
+// decodeBatchResults decodes a JSONL stream of batch results.
+func decodeBatchResults(r io.Reader) (*[]BatchResult, error) {
+	var results []BatchResult
+	dec := json.NewDecoder(r)
+	for {
+		var res BatchResult
+		if err := dec.Decode(&res); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("decode batch results: %w", err)
+		}
+		if res.Error != nil {
+			res.Error.fillSentinel()
+		}
+		results = append(results, res)
+	}
+	return &results, nil
+}
+
+// RunBatchOptions configures BatchesService.Run.
+type RunBatchOptions struct {
+	// PollInterval is how often the server-side job is polled (default 5s).
+	PollInterval time.Duration
+	// Concurrency bounds the local fallback executor (default 4).
+	Concurrency int
+	// Metadata is attached to the server-side job.
+	Metadata map[string]string
+}
+
+// Run executes requests as a server-side batch job and waits for the results.
+// If the server has no batch endpoint it runs the same requests locally
+// through Chat.Create instead. Results are returned in the order of requests.
+func (s *BatchesService) Run(ctx context.Context, requests []BatchRequest, opts RunBatchOptions, reqOpts ...RequestOption) ([]BatchResult, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	batch, err := s.Create(ctx, CreateBatchParams{Requests: requests, Metadata: opts.Metadata})
+	if errors.Is(err, ErrBatchUnavailable) {
+		return s.RunLocal(ctx, requests, opts.Concurrency)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err = s.Wait(ctx, batch.ID, opts.PollInterval)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status != BatchCompleted {
+		return nil, fmt.Errorf("cencori: batch %s finished with status %s", batch.ID, batch.Status)
+	}
+
+	results, err := s.Results(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+	return orderResults(requests, results), nil
+}
+
+// RunLocal executes requests through Chat.CreateMany with at most
+// concurrency requests in flight. Per-request failures are reported in
+// BatchResult.Error; the returned error is only set when ctx is done.
+func (s *BatchesService) RunLocal(ctx context.Context, requests []BatchRequest, concurrency int, opts ...RequestOption) ([]BatchResult, error) {
+	ctx = withRequestOptions(ctx, opts)
+	if err := validateBatch(requests); err != nil {
+		return nil, err
+	}
+
+	params := CreateManyParams{Requests: make([]ChatParams, len(requests)), Concurrency: concurrency}
+	for i, req := range requests {
+		params.Requests[i] = req.Params
+	}
+	resp, err := s.client.Chat.CreateMany(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(requests))
+	for i, r := range resp.Results {
+		results[i] = BatchResult{CustomID: requests[i].CustomID, Response: r.Response, Error: asAPIError(r.Err)}
+	}
+	return results, nil
+}
+
+// orderResults arranges results in request order. Requests the server
+// returned nothing for get a result with an error.
+func orderResults(requests []BatchRequest, results []BatchResult) []BatchResult {
+	byID := make(map[string]BatchResult, len(results))
+	for _, r := range results {
+		byID[r.CustomID] = r
+	}
+
+	out := make([]BatchResult, len(requests))
+	for i, req := range requests {
+		r, ok := byID[req.CustomID]
+		if !ok {
+			r = BatchResult{CustomID: req.CustomID, Error: &APIError{Message: "no result returned for request"}}
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// asAPIError converts err to an *APIError, wrapping non-API errors so that
+// errors.Is still matches the original.
+func asAPIError(err error) *APIError {
+	if err == nil {
+		return nil
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return &APIError{Message: err.Error(), Err: err}
+}
+
+// isUnavailable reports whether err means the endpoint does not exist on the server.
+func isUnavailable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package cencori
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReadBatchRequests(t *testing.T) {
+	input := `{"custom_id":"a","params":{"model":"gpt-4o","messages":[{"role":"user","content":"Hi"}]}}
+
+{"params":{"model":"gpt-4o","messages":[{"role":"user","content":"Bye"}]}}
+`
+	reqs, err := ReadBatchRequests(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(reqs))
+	}
+	if reqs[0].CustomID != "a" || reqs[1].CustomID != "request-3" {
+		t.Errorf("unexpected custom IDs: %q, %q", reqs[0].CustomID, reqs[1].CustomID)
+	}
+	if reqs[1].Params.Messages[0].Content != "Bye" {
+		t.Errorf("params not decoded: %+v", reqs[1].Params)
+	}
+
+	if _, err := ReadBatchRequests(strings.NewReader("{bad json}\n")); err == nil {
+		t.Error("expected an error for malformed input")
+	}
+}
+
+func TestBatches_RunServerSide(t *testing.T) {
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v1/batches":
+			var params CreateBatchParams
+			json.NewDecoder(r.Body).Decode(&params)
+			if len(params.Requests) != 2 || params.Requests[0].CustomID != "first" {
+				t.Errorf("unexpected batch payload: %+v", params)
+			}
+			json.NewEncoder(w).Encode(Batch{ID: "batch-1", Status: BatchValidating})
+		case r.URL.Path == "/api/v1/batches/batch-1":
+			status := BatchInProgress
+			if polls.Add(1) > 1 {
+				status = BatchCompleted
+			}
+			json.NewEncoder(w).Encode(Batch{ID: "batch-1", Status: status})
+		case r.URL.Path == "/api/v1/batches/batch-1/results":
+			// Results arrive out of order; Run must restore request order.
+			WriteBatchResults(w, []BatchResult{
+				{CustomID: "second", Error: &APIError{StatusCode: 429, Code: "RATE_LIMIT_EXCEEDED", Message: "slow down"}},
+				{CustomID: "first", Response: &ChatResponse{ID: "chat-1"}},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	results, err := client.Batches.Run(context.Background(), []BatchRequest{
+		{CustomID: "first", Params: ChatParams{Model: "gpt-4o"}},
+		{CustomID: "second", Params: ChatParams{Model: "gpt-4o"}},
+	}, RunBatchOptions{PollInterval: 1})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if results[0].CustomID != "first" || results[0].Response == nil || results[0].Response.ID != "chat-1" {
+		t.Errorf("unexpected first result: %+v", results[0])
+	}
+	if results[1].Error == nil || !errors.Is(results[1].Error, ErrRateLimited) {
+		t.Errorf("expected second result to be rate limited, got %+v", results[1])
+	}
+	if polls.Load() != 2 {
+		t.Errorf("expected 2 status polls, got %d", polls.Load())
+	}
+}
+
+func TestBatches_CreateSendsRequestsAsGiven(t *testing.T) {
+	var sent CreateBatchParams
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		json.NewEncoder(w).Encode(Batch{ID: "batch-1", Status: BatchValidating})
+	}))
+	defer server.Close()
+
+	redactor := NewRedactor(RedactionConfig{})
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithMiddleware(redactor.Middleware()))
+
+	requests := []BatchRequest{{CustomID: "first", Params: ChatParams{
+		Model:    "gpt-4o",
+		Messages: []Message{{Role: "user", Content: "Email jane@example.com"}},
+		Stream:   true,
+	}}}
+	if _, err := client.Batches.Create(context.Background(), CreateBatchParams{Requests: requests}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Server-side batches bypass chat middleware, as documented.
+	if got := sent.Requests[0].Params.Messages[0].Content; got != "Email jane@example.com" {
+		t.Errorf("expected the batch to be submitted unredacted, got %q", got)
+	}
+	if sent.Requests[0].Params.Stream {
+		t.Error("expected streaming to be disabled in the submitted batch")
+	}
+	if !requests[0].Params.Stream {
+		t.Error("expected the caller's requests not to be modified")
+	}
+}
+
+func TestBatches_ResultsRunsHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Audit") != "1" {
+			t.Errorf("expected OnRequest to set headers")
+		}
+		WriteBatchResults(w, []BatchResult{{CustomID: "first", Response: &ChatResponse{ID: "chat-1"}}})
+	}))
+	defer server.Close()
+
+	log := &hookLog{}
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithHooks(log.hooks()))
+
+	results, err := client.Batches.Results(context.Background(), "batch-1")
+	if err != nil || len(results) != 1 || results[0].Response.ID != "chat-1" {
+		t.Fatalf("unexpected results: %+v, %v", results, err)
+	}
+	want := []string{"request <nil> /api/v1/batches/batch-1/results", "response 200 *[]cencori.BatchResult"}
+	if strings.Join(log.events, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected hook events: %q", log.events)
+	}
+}
+
+func TestBatches_RunFallsBackToLocal(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/batches" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			return
+		}
+
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		var params ChatParams
+		json.NewDecoder(r.Body).Decode(&params)
+		if params.Messages[0].Content == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "bad model", "code": "INVALID_MODEL"})
+			return
+		}
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-" + params.Messages[0].Content})
+	}))
+	defer server.Close()
+
+	var input bytes.Buffer
+	for _, content := range []string{"a", "b", "fail", "c", "d"} {
+		json.NewEncoder(&input).Encode(BatchRequest{
+			CustomID: content,
+			Params:   ChatParams{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: content}}},
+		})
+	}
+	reqs, err := ReadBatchRequests(&input)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	results, err := client.Batches.Run(context.Background(), reqs, RunBatchOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for i, r := range results {
+		if r.CustomID != reqs[i].CustomID {
+			t.Errorf("result %d has custom ID %q, want %q", i, r.CustomID, reqs[i].CustomID)
+		}
+		if r.CustomID == "fail" {
+			if !errors.Is(r.Error, ErrInvalidModel) {
+				t.Errorf("expected ErrInvalidModel, got %v", r.Error)
+			}
+			continue
+		}
+		if r.Error != nil || r.Response.ID != "chat-"+r.CustomID {
+			t.Errorf("unexpected result: %+v", r)
+		}
+	}
+	if maxInFlight.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxInFlight.Load())
+	}
+}
+
+func TestBatches_DuplicateCustomID(t *testing.T) {
+	client := &Client{APIKey: "test-key", BaseURL: "http://unused", httpClient: http.DefaultClient}
+	client.Batches = &BatchesService{client: client}
+
+	_, err := client.Batches.Create(context.Background(), CreateBatchParams{Requests: []BatchRequest{
+		{CustomID: "x"}, {CustomID: "x"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("expected a duplicate custom_id error, got %v", err)
+	}
+}
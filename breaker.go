@@ -0,0 +1,351 @@
+package cencori
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a single circuit.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through and tracks the failure rate.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects requests immediately with a *CircuitOpenError.
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of probe requests through to
+	// decide whether the circuit should close again.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerConfig configures the client-side circuit breaker.
+// Zero values are replaced with sensible defaults.
+type BreakerConfig struct {
+	// KeyFunc maps a model name to a circuit key. The default keeps one
+	// circuit per model; use ProviderKey to share a circuit per provider.
+	KeyFunc func(model string) string
+	// Window is the period over which failures are counted (default 60s).
+	Window time.Duration
+	// MinRequests is the number of requests required in a window before
+	// the failure ratio is evaluated (default 10).
+	MinRequests int
+	// FailureRatio opens the circuit once failures/requests reaches it (default 0.5).
+	FailureRatio float64
+	// OpenTimeout is how long the circuit stays open before probing (default 30s).
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of concurrent probes allowed while
+	// half-open (default 1).
+	HalfOpenRequests int
+}
+
+func (cfg *BreakerConfig) setDefaults() {
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = func(model string) string { return model }
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 60 * time.Second
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 10
+	}
+	if cfg.FailureRatio <= 0 || cfg.FailureRatio > 1 {
+		cfg.FailureRatio = 0.5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+}
+
+// ProviderKey maps a model name to its upstream provider so that all models
+// served by the same provider share one circuit. Unknown models map to themselves.
+func ProviderKey(model string) string {
+	if provider, _, ok := strings.Cut(model, "/"); ok {
+		return provider
+	}
+	switch {
+	case strings.HasPrefix(model, "gemini-"), model == "text-embedding-004":
+		return "google"
+	case strings.HasPrefix(model, "gpt-"), strings.HasPrefix(model, "text-embedding-"):
+		return "openai"
+	case strings.HasPrefix(model, "claude-"):
+		return "anthropic"
+	default:
+		return model
+	}
+}
+
+// CircuitOpenError is returned without contacting the API when the circuit
+// for a model or provider is open.
+type CircuitOpenError struct {
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("cencori: circuit open for %q (retry in %s)", e.Key, e.RetryAfter.Round(time.Millisecond))
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// BreakerStatus is a point-in-time view of one circuit, suitable for health checks.
+type BreakerStatus struct {
+	Key      string       `json:"key"`
+	State    BreakerState `json:"state"`
+	Requests int          `json:"requests"`
+	Failures int          `json:"failures"`
+	OpenedAt time.Time    `json:"opened_at,omitzero"`
+}
+
+type circuit struct {
+	state       BreakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	halfOpenAt  time.Time
+	probes      int
+	// generation changes whenever the circuit opens or closes, so that
+	// requests started under an earlier state are not counted against it.
+	generation uint64
+}
+
+// CircuitBreaker tracks upstream health per model or provider and fails fast
+// while a circuit is open. A nil *CircuitBreaker allows every request.
+type CircuitBreaker struct {
+	cfg      BreakerConfig
+	now      func() time.Time
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	gen      uint64
+}
+
+// NewCircuitBreaker creates a circuit breaker with the given configuration.
+func NewCircuitBreaker(cfg BreakerConfig) *CircuitBreaker {
+	cfg.setDefaults()
+	return &CircuitBreaker{
+		cfg:      cfg,
+		now:      time.Now,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// State returns the current state of the circuit for key.
+func (cb *CircuitBreaker) State(key string) BreakerState {
+	if cb == nil {
+		return BreakerClosed
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c, ok := cb.circuits[key]
+	if !ok {
+		return BreakerClosed
+	}
+	cb.advance(c)
+	return c.state
+}
+
+// Snapshot returns the status of every known circuit, sorted by key.
+func (cb *CircuitBreaker) Snapshot() []BreakerStatus {
+	if cb == nil {
+		return nil
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	out := make([]BreakerStatus, 0, len(cb.circuits))
+	for key, c := range cb.circuits {
+		cb.advance(c)
+		out = append(out, BreakerStatus{
+			Key:      key,
+			State:    c.state,
+			Requests: c.requests,
+			Failures: c.failures,
+			OpenedAt: c.openedAt,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// Reset forces the circuit for key back to closed.
+func (cb *CircuitBreaker) Reset(key string) {
+	if cb == nil {
+		return
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	delete(cb.circuits, key)
+}
+
+// advance moves an open circuit to half-open once its timeout has elapsed
+// and rolls the counting window of a closed circuit. Callers hold cb.mu.
+func (cb *CircuitBreaker) advance(c *circuit) {
+	now := cb.now()
+	switch c.state {
+	case BreakerOpen:
+		if now.Sub(c.openedAt) >= cb.cfg.OpenTimeout {
+			c.state = BreakerHalfOpen
+			c.halfOpenAt = now
+			c.probes = 0
+		}
+	case BreakerClosed:
+		if now.Sub(c.windowStart) >= cb.cfg.Window {
+			c.windowStart = now
+			c.requests, c.failures = 0, 0
+		}
+	case BreakerHalfOpen:
+	}
+}
+
+// acquire reserves a slot on the circuit for model. The returned function
+// must be called exactly once with the outcome of the request. Outcomes
+// after ctx is done are not counted: the caller's own deadline or
+// cancellation says nothing about upstream health.
+func (cb *CircuitBreaker) acquire(ctx context.Context, model string) (func(error), error) {
+	if cb == nil {
+		return func(error) {}, nil
+	}
+	key := cb.cfg.KeyFunc(model)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c, ok := cb.circuits[key]
+	if !ok {
+		c = cb.newCircuit()
+		cb.circuits[key] = c
+	}
+	cb.advance(c)
+
+	switch c.state {
+	case BreakerOpen:
+		return nil, &CircuitOpenError{Key: key, RetryAfter: cb.cfg.OpenTimeout - cb.now().Sub(c.openedAt)}
+	case BreakerHalfOpen:
+		if c.probes >= cb.cfg.HalfOpenRequests {
+			// Probes are given one OpenTimeout to report back.
+			retryAfter := cb.cfg.OpenTimeout - cb.now().Sub(c.halfOpenAt)
+			if retryAfter <= 0 {
+				retryAfter = cb.cfg.OpenTimeout
+			}
+			return nil, &CircuitOpenError{Key: key, RetryAfter: retryAfter}
+		}
+		c.probes++
+	case BreakerClosed:
+	}
+
+	gen := c.generation
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { cb.record(key, gen, err, ctx.Err() != nil) })
+	}, nil
+}
+
+// record counts the outcome of a request started in generation gen of the
+// circuit for key. Outcomes from an earlier generation are ignored: they
+// neither hold a probe slot nor say anything about the current state.
+// callerDone reports that the caller's context ended, in which case only
+// the probe slot is released.
+func (cb *CircuitBreaker) record(key string, gen uint64, err error, callerDone bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c, ok := cb.circuits[key]
+	if !ok || c.generation != gen {
+		return
+	}
+
+	if c.state == BreakerHalfOpen {
+		c.probes--
+	}
+	if callerDone || errors.Is(err, context.Canceled) {
+		// The caller gave up; this says nothing about upstream health.
+		return
+	}
+
+	failed := isBreakerFailure(err)
+	switch c.state {
+	case BreakerHalfOpen:
+		if failed {
+			cb.trip(c)
+			return
+		}
+		*c = *cb.newCircuit()
+	case BreakerClosed:
+		c.requests++
+		if failed {
+			c.failures++
+		}
+		if c.requests >= cb.cfg.MinRequests &&
+			float64(c.failures)/float64(c.requests) >= cb.cfg.FailureRatio {
+			cb.trip(c)
+		}
+	case BreakerOpen:
+	}
+}
+
+// newCircuit returns a closed circuit in a new generation. Callers hold cb.mu.
+func (cb *CircuitBreaker) newCircuit() *circuit {
+	cb.gen++
+	return &circuit{windowStart: cb.now(), generation: cb.gen}
+}
+
+func (cb *CircuitBreaker) trip(c *circuit) {
+	cb.gen++
+	c.state = BreakerOpen
+	c.openedAt = cb.now()
+	c.probes = 0
+	c.generation = cb.gen
+}
+
+// isBreakerFailure reports whether err indicates an unhealthy upstream:
+// provider errors, 5xx responses and transport failures such as timeouts.
+// Client errors (4xx) mean the upstream answered and do not count.
+func isBreakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrProvider) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// guard runs fn under the circuit for model.
+func guard[T any](c *Client, ctx context.Context, model string, fn func() (*T, error)) (*T, error) {
+	release, err := c.Breaker.acquire(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	res, err := fn()
+	release(err)
+	return res, err
+}
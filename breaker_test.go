@@ -0,0 +1,242 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_OpensOnProviderErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(502)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "upstream down",
+			"code":  "PROVIDER_ERROR",
+		})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithCircuitBreaker(BreakerConfig{MinRequests: 3, FailureRatio: 0.5}),
+	)
+
+	for range 3 {
+		_, err := client.Chat.Create(context.Background(), &ChatParams{Model: "gpt-4o"})
+		if !errors.Is(err, ErrProvider) {
+			t.Fatalf("expected ErrProvider, got %v", err)
+		}
+	}
+
+	_, err := client.Chat.Create(context.Background(), &ChatParams{Model: "gpt-4o"})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected *CircuitOpenError, got %T", err)
+	}
+	if openErr.Key != "gpt-4o" {
+		t.Errorf("expected key gpt-4o, got %s", openErr.Key)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 upstream calls, got %d", calls.Load())
+	}
+	if state := client.Breaker.State("gpt-4o"); state != BreakerOpen {
+		t.Errorf("expected open state, got %s", state)
+	}
+
+	// Other models are unaffected.
+	if state := client.Breaker.State("claude-3-sonnet"); state != BreakerClosed {
+		t.Errorf("expected closed state, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Invalid model",
+			"code":  "INVALID_MODEL",
+		})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithCircuitBreaker(BreakerConfig{MinRequests: 2}),
+	)
+
+	for range 5 {
+		_, err := client.Chat.Create(context.Background(), &ChatParams{Model: "nope"})
+		if !errors.Is(err, ErrInvalidModel) {
+			t.Fatalf("expected ErrInvalidModel, got %v", err)
+		}
+	}
+}
+
+func TestCircuitBreaker_HalfOpenRecovery(t *testing.T) {
+	now := time.Now()
+	cb := NewCircuitBreaker(BreakerConfig{MinRequests: 1, OpenTimeout: time.Minute})
+	cb.now = func() time.Time { return now }
+
+	release, err := cb.acquire(context.Background(), "gpt-4o")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release(&APIError{StatusCode: 503})
+
+	if _, err := cb.acquire(context.Background(), "gpt-4o"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if state := cb.State("gpt-4o"); state != BreakerHalfOpen {
+		t.Fatalf("expected half-open, got %s", state)
+	}
+
+	probe, err := cb.acquire(context.Background(), "gpt-4o")
+	if err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	if _, err := cb.acquire(context.Background(), "gpt-4o"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected second probe to be rejected, got %v", err)
+	}
+
+	probe(nil)
+	if state := cb.State("gpt-4o"); state != BreakerClosed {
+		t.Fatalf("expected closed after successful probe, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_StaleReleaseDuringHalfOpen(t *testing.T) {
+	now := time.Now()
+	cb := NewCircuitBreaker(BreakerConfig{MinRequests: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+	cb.now = func() time.Time { return now }
+
+	// A slow request starts while the circuit is closed...
+	slow, err := cb.acquire(context.Background(), "gpt-4o")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// ...then another request trips it.
+	failing, _ := cb.acquire(context.Background(), "gpt-4o")
+	failing(&APIError{StatusCode: 503})
+
+	now = now.Add(time.Minute)
+	probe, err := cb.acquire(context.Background(), "gpt-4o")
+	if err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+
+	// The pre-trip request finishing must neither close the circuit nor
+	// free the probe slot.
+	slow(nil)
+	if state := cb.State("gpt-4o"); state != BreakerHalfOpen {
+		t.Fatalf("expected half-open while the probe is in flight, got %s", state)
+	}
+	if _, err := cb.acquire(context.Background(), "gpt-4o"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected second probe to be rejected, got %v", err)
+	}
+
+	probe(&APIError{StatusCode: 503})
+	if state := cb.State("gpt-4o"); state != BreakerOpen {
+		t.Fatalf("expected the failed probe to reopen the circuit, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenRetryAfter(t *testing.T) {
+	now := time.Now()
+	cb := NewCircuitBreaker(BreakerConfig{MinRequests: 1, OpenTimeout: time.Minute})
+	cb.now = func() time.Time { return now }
+
+	release, _ := cb.acquire(context.Background(), "gpt-4o")
+	release(&APIError{StatusCode: 503})
+	now = now.Add(time.Minute)
+	if _, err := cb.acquire(context.Background(), "gpt-4o"); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+
+	now = now.Add(20 * time.Second)
+	_, err := cb.acquire(context.Background(), "gpt-4o")
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || openErr.RetryAfter != 40*time.Second {
+		t.Fatalf("expected the remaining probe window of 40s, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_IgnoresCallerDeadline(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConfig{MinRequests: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	release, err := cb.acquire(ctx, "gpt-4o")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release(fmt.Errorf("send request: %w", ctx.Err()))
+	if state := cb.State("gpt-4o"); state != BreakerClosed {
+		t.Fatalf("expected the caller's deadline not to trip the circuit, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_ProviderKey(t *testing.T) {
+	tests := map[string]string{
+		"gpt-4o":                 "openai",
+		"text-embedding-3-small": "openai",
+		"claude-3-sonnet":        "anthropic",
+		"gemini-pro":             "google",
+		"text-embedding-004":     "google",
+		"mistral/mistral-large":  "mistral",
+		"custom-model":           "custom-model",
+	}
+	for model, want := range tests {
+		if got := ProviderKey(model); got != want {
+			t.Errorf("ProviderKey(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
+
+func TestCircuitBreaker_StreamFailuresCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"error\": \"boom\", \"code\": \"PROVIDER_ERROR\"}\n\n"))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithCircuitBreaker(BreakerConfig{MinRequests: 1, KeyFunc: ProviderKey}),
+	)
+
+	stream, err := client.Chat.Stream(context.Background(), &ChatParams{Model: "claude-3-sonnet"})
+	if err != nil {
+		t.Fatalf("failed to start stream: %v", err)
+	}
+	for range stream {
+	}
+
+	_, err = client.Chat.Stream(context.Background(), &ChatParams{Model: "claude-3-haiku"})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen for same provider, got %v", err)
+	}
+
+	snapshot := client.Breaker.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Key != "anthropic" || snapshot[0].State != BreakerOpen {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+}
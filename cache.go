@@ -0,0 +1,286 @@
+package cencori
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache stores serialized API responses. Implement it to plug in a shared
+// backend such as Redis. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key. A zero ttl means the entry never expires.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// CacheConfig configures the exact-match response cache.
+type CacheConfig struct {
+	// Backend stores the cached responses (default: an in-memory LRU of 1000 entries).
+	Backend Cache
+	// TTL is how long a response stays cached. Zero means forever.
+	TTL time.Duration
+	// CacheNonDeterministic also caches chat requests whose temperature is
+	// unset or above zero. By default only temperature 0 requests are cached.
+	CacheNonDeterministic bool
+}
+
+// responseCache wraps a Cache backend with key derivation and (de)serialization.
+type responseCache struct {
+	cfg CacheConfig
+}
+
+func newResponseCache(cfg CacheConfig) *responseCache {
+	if cfg.Backend == nil {
+		cfg.Backend = NewMemoryCache(1000)
+	}
+	return &responseCache{cfg: cfg}
+}
+
+// cacheKey returns a canonical hash of the scope, the request kind and its
+// parameters.
+func cacheKey(scope, kind string, params any) (string, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("marshal cache key: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(scope+"\x00"+kind+":"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cacheScope identifies whose responses the call in ctx may share: its
+// effective API key and base URL. Responses are never served to a different
+// key or gateway, even when the backend is shared between clients.
+func (c *Client) cacheScope(ctx context.Context) (string, error) {
+	key, err := c.apiKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	base := c.BaseURL
+	if o := requestOptionsFrom(ctx); o != nil && o.baseURL != "" {
+		base = o.baseURL
+	}
+	return key + "\x00" + base, nil
+}
+
+// isDeterministic reports whether a chat request is expected to produce the
+// same output every time it is sent.
+func (p *ChatParams) isDeterministic() bool {
+	return p.Temperature != nil && *p.Temperature == 0
+}
+
+// forChat returns rc if the chat request may be cached, or nil otherwise.
+func (rc *responseCache) forChat(params *ChatParams) *responseCache {
+	if rc == nil || (!params.isDeterministic() && !rc.cfg.CacheNonDeterministic) {
+		return nil
+	}
+	return rc
+}
+
+type cacheable interface {
+	setCacheHit()
+}
+
+func (r *ChatResponse) setCacheHit()      { r.CacheHit = true }
+func (r *EmbeddingResponse) setCacheHit() { r.CacheHit = true }
+
+// cached serves a response from rc when possible and otherwise calls fn and
+// stores its result. Backend failures are treated as cache misses so that the
+// cache never makes a request fail. A nil rc calls fn directly.
+func cached[Req any, Resp any, PR interface {
+	*Resp
+	cacheable
+}](rc *responseCache, ctx context.Context, c *Client, kind string, params *Req, fn func() (*Resp, error)) (*Resp, error) {
+	if rc == nil {
+		return fn()
+	}
+
+	scope, err := c.cacheScope(ctx)
+	if err != nil {
+		return fn()
+	}
+	key, err := cacheKey(scope, kind, params)
+	if err != nil {
+		return fn()
+	}
+
+	if data, ok, err := rc.cfg.Backend.Get(ctx, key); err == nil && ok {
+		var resp Resp
+		if err := json.Unmarshal(data, &resp); err == nil {
+			PR(&resp).setCacheHit()
+			return &resp, nil
+		}
+	}
+
+	resp, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(resp); err == nil {
+		_ = rc.cfg.Backend.Set(ctx, key, data, rc.cfg.TTL) //nolint:errcheck // A failed write only costs a future cache miss.
+	}
+	return resp, nil
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache is an in-process LRU Cache.
+type MemoryCache struct {
+	maxEntries int
+	now        func() time.Time
+	mu         sync.Mutex
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+// NewMemoryCache creates an LRU cache holding at most maxEntries responses.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryEntry) //nolint:errcheck // Only *memoryEntry values are stored.
+	if !entry.expiresAt.IsZero() && m.now().After(entry.expiresAt) {
+		m.ll.Remove(el)
+		delete(m.items, key)
+		return nil, false, nil
+	}
+	m.ll.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+// Set implements Cache.
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = m.now().Add(ttl)
+	}
+
+	if el, ok := m.items[key]; ok {
+		el.Value = &memoryEntry{key: key, value: value, expiresAt: expiresAt}
+		m.ll.MoveToFront(el)
+		return nil
+	}
+
+	m.items[key] = m.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for m.ll.Len() > m.maxEntries {
+		oldest := m.ll.Back()
+		m.ll.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryEntry).key) //nolint:errcheck // Only *memoryEntry values are stored.
+	}
+	return nil
+}
+
+// Len returns the number of cached entries, including expired ones not yet evicted.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+// DiskCache is a Cache that stores one file per entry in a directory.
+// It survives process restarts, which suits repeated CI and eval runs.
+type DiskCache struct {
+	dir string
+	now func() time.Time
+}
+
+type diskEntry struct {
+	ExpiresAt time.Time       `json:"expires_at,omitzero"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// NewDiskCache creates a DiskCache rooted at dir, creating it if needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	return &DiskCache{dir: dir, now: time.Now}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	return filepath.Join(d.dir, key+".json")
+}
+
+// Get implements Cache.
+func (d *DiskCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("read cache entry: %w", err)
+	}
+
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false, fmt.Errorf("decode cache entry: %w", err)
+	}
+	if !entry.ExpiresAt.IsZero() && d.now().After(entry.ExpiresAt) {
+		os.Remove(d.path(key)) //nolint:errcheck // Expired entries are rewritten on the next Set anyway.
+		return nil, false, nil
+	}
+	return entry.Value, true, nil
+}
+
+// Set implements Cache. Entries are written atomically.
+func (d *DiskCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := diskEntry{Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = d.now().Add(ttl)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(d.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // The temp file is gone after a successful rename.
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck // The write error is more useful.
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_ChatCreate(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123", Usage: Usage{TotalTokens: 7}})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithCache(CacheConfig{}))
+
+	temp := 0.0
+	params := func(content string) *ChatParams {
+		return &ChatParams{
+			Model:       "gpt-4o",
+			Temperature: &temp,
+			Messages:    []Message{{Role: "user", Content: content}},
+		}
+	}
+
+	first, err := client.Chat.Create(context.Background(), params("Hi"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if first.CacheHit {
+		t.Error("first response should not be a cache hit")
+	}
+
+	second, err := client.Chat.Create(context.Background(), params("Hi"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !second.CacheHit {
+		t.Error("second response should be a cache hit")
+	}
+	if second.ID != "chat-123" || second.Usage.TotalTokens != 7 {
+		t.Errorf("cached response not decoded correctly: %+v", second)
+	}
+
+	if _, err := client.Chat.Create(context.Background(), params("Different")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 upstream calls, got %d", calls.Load())
+	}
+}
+
+func TestCache_ScopedByKeyAndBaseURL(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(ChatResponse{ID: r.Header.Get("CENCORI_API_KEY")})
+	}))
+	defer server.Close()
+
+	backend := NewMemoryCache(10)
+	tenantA, _ := NewClient(WithAPIKey("key-a"), WithBaseURL(server.URL), WithCache(CacheConfig{Backend: backend}))
+	tenantB, _ := NewClient(WithAPIKey("key-b"), WithBaseURL(server.URL), WithCache(CacheConfig{Backend: backend}))
+
+	temp := 0.0
+	params := &ChatParams{Model: "gpt-4o", Temperature: &temp, Messages: []Message{{Role: "user", Content: "Hi"}}}
+
+	if _, err := tenantA.Chat.Create(context.Background(), params); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	resp, err := tenantB.Chat.Create(context.Background(), params)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.CacheHit || resp.ID != "key-b" {
+		t.Errorf("expected a response for key-b, got %+v", resp)
+	}
+
+	if resp, _ := tenantA.Chat.Create(context.Background(), params, WithRequestAPIKey("key-c")); resp.CacheHit {
+		t.Error("expected a per-call API key not to share the client's entries")
+	}
+	if resp, _ := tenantA.Chat.Create(context.Background(), params, WithRequestBaseURL(server.URL+"/")); resp.CacheHit {
+		t.Error("expected a per-call base URL not to share the client's entries")
+	}
+	if resp, _ := tenantA.Chat.Create(context.Background(), params); !resp.CacheHit {
+		t.Error("expected the same key and base URL to hit the cache")
+	}
+	if calls.Load() != 4 {
+		t.Errorf("expected 4 upstream calls, got %d", calls.Load())
+	}
+}
+
+func TestCache_SkipsNonDeterministic(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123"})
+	}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		name  string
+		force bool
+		want  int32
+	}{
+		{"default", false, 2},
+		{"forced", true, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			client, _ := NewClient(
+				WithAPIKey("test-key"),
+				WithBaseURL(server.URL),
+				WithCache(CacheConfig{CacheNonDeterministic: tt.force}),
+			)
+
+			for range 2 {
+				_, err := client.Chat.Create(context.Background(), &ChatParams{
+					Model:    "gpt-4o",
+					Messages: []Message{{Role: "user", Content: "Hi"}},
+				})
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+			}
+			if calls.Load() != tt.want {
+				t.Errorf("expected %d upstream calls, got %d", tt.want, calls.Load())
+			}
+		})
+	}
+}
+
+func TestCache_Embeddings(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(EmbeddingResponse{
+			Data: []EmbeddingData{{Embedding: []float32{0.1, 0.2}}},
+		})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	for range 2 {
+		backend, err := NewDiskCache(dir)
+		if err != nil {
+			t.Fatalf("failed to create disk cache: %v", err)
+		}
+		client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithCache(CacheConfig{Backend: backend}))
+
+		resp, err := client.Chat.Embeddings(context.Background(), EmbeddingParams{Input: EmbeddingText("Hello"), Model: "text-embedding-3-small"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(resp.Data) != 1 || len(resp.Data[0].Embedding) != 2 {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("expected the disk cache to survive a new client, got %d upstream calls", calls.Load())
+	}
+}
+
+func TestMemoryCache_LRUAndTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := NewMemoryCache(2)
+	cache.now = func() time.Time { return now }
+
+	cache.Set(ctx, "a", []byte("1"), 0)
+	cache.Set(ctx, "b", []byte("2"), time.Minute)
+	cache.Get(ctx, "a") // a is now most recently used
+	cache.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if v, ok, _ := cache.Get(ctx, "a"); !ok || string(v) != "1" {
+		t.Errorf("expected a to survive eviction, got %q", v)
+	}
+
+	cache.Set(ctx, "d", []byte("4"), time.Minute)
+	now = now.Add(2 * time.Minute)
+	if _, ok, _ := cache.Get(ctx, "d"); ok {
+		t.Error("expected d to expire")
+	}
+}
+
+func TestDiskCache_TTL(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create disk cache: %v", err)
+	}
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	if err := cache.Set(ctx, "key", []byte(`{"id":"x"}`), time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if v, ok, err := cache.Get(ctx, "key"); err != nil || !ok || string(v) != `{"id":"x"}` {
+		t.Fatalf("Get = %q, %v, %v", v, ok, err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok, _ := cache.Get(ctx, "key"); ok {
+		t.Error("expected entry to expire")
+	}
+}
@@ -2,7 +2,6 @@ package cencori
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -21,15 +20,28 @@ type ChatService struct {
 // It disables streaming and makes a synchronous request to the /api/ai/chat endpoint.
 // The context can be used to cancel the request or set a timeout.
 // It returns a ChatResponse on success or an error if the request fails.
-func (s *ChatService) Create(ctx context.Context, params *ChatParams) (*ChatResponse, error) {
+func (s *ChatService) Create(ctx context.Context, params *ChatParams, opts ...RequestOption) (*ChatResponse, error) {
+	ctx = s.client.withClient(withRequestOptions(ctx, opts))
 	params.Stream = false
-	return doRequest[ChatParams, ChatResponse](s.client, ctx, "POST", "/api/ai/chat", params)
+	resp, err := s.client.wrapCreate(s.create)(ctx, params)
+	if err == nil {
+		s.client.recordCacheHit("chat", params.Model, resp.CacheHit)
+	}
+	return resp, err
+}
+
+func (s *ChatService) create(ctx context.Context, params *ChatParams) (*ChatResponse, error) {
+	return cached(s.client.cache.forChat(params), ctx, s.client, "chat", params, func() (*ChatResponse, error) {
+		return guard(s.client, ctx, params.Model, func() (*ChatResponse, error) {
+			return doRequest[ChatParams, ChatResponse](s.client, ctx, "POST", "/api/ai/chat", params)
+		})
+	})
 }
 
 // Completions is a convenience method that wraps Create for simple text completions.
 // It takes a single prompt string and converts it to a chat message internally.
 // This is useful for quick, single-turn completions without managing conversation history.
-func (s *ChatService) Completions(ctx context.Context, params CompletionParams) (*ChatResponse, error) {
+func (s *ChatService) Completions(ctx context.Context, params CompletionParams, opts ...RequestOption) (*ChatResponse, error) {
 	chatParams := &ChatParams{
 		Model:       params.Model,
 		Temperature: params.Temperature,
@@ -38,14 +50,30 @@ func (s *ChatService) Completions(ctx context.Context, params CompletionParams)
 			{Role: "user", Content: params.Prompt},
 		},
 	}
-	return s.Create(ctx, chatParams)
+	return s.Create(ctx, chatParams, opts...)
 }
 
-// Embeddings generates vector embeddings for the given input text(s).
-// Input can be a single string or a slice of strings.
+// Embeddings generates vector embeddings for the given input.
+// Build the input with EmbeddingText, EmbeddingTexts, EmbeddingTokens or EmbeddingTokenBatches.
 // Returns an EmbeddingResponse containing the embeddings and token usage.
-func (s *ChatService) Embeddings(ctx context.Context, params EmbeddingParams) (*EmbeddingResponse, error) {
-	return doRequest[EmbeddingParams, EmbeddingResponse](s.client, ctx, "POST", "/api/v1/embeddings", &params)
+func (s *ChatService) Embeddings(ctx context.Context, params EmbeddingParams, opts ...RequestOption) (*EmbeddingResponse, error) {
+	ctx = s.client.withClient(withRequestOptions(ctx, opts))
+	resp, err := s.client.wrapEmbeddings(s.embeddings)(ctx, params)
+	if err == nil {
+		s.client.recordCacheHit("embeddings", params.Model, resp.CacheHit)
+	}
+	return resp, err
+}
+
+func (s *ChatService) embeddings(ctx context.Context, params EmbeddingParams) (*EmbeddingResponse, error) {
+	if params.Input.Len() == 0 {
+		return nil, errEmptyEmbeddingInput
+	}
+	return cached(s.client.cache, ctx, s.client, "embeddings", &params, func() (*EmbeddingResponse, error) {
+		return guard(s.client, ctx, params.Model, func() (*EmbeddingResponse, error) {
+			return doRequest[EmbeddingParams, EmbeddingResponse](s.client, ctx, "POST", "/api/v1/embeddings", &params)
+		})
+	})
 }
 
 // Stream sends a chat request with streaming enabled and returns a channel that receives
@@ -53,44 +81,70 @@ func (s *ChatService) Embeddings(ctx context.Context, params EmbeddingParams) (*
 // sends a "[DONE]" message or an error occurs. The context can be used to cancel the stream.
 // If the context is cancelled, it simply closes.
 // The returned channel will be closed when the stream ends or an error occurs.
-func (s *ChatService) Stream(ctx context.Context, params *ChatParams) (<-chan StreamChunk, error) {
+func (s *ChatService) Stream(ctx context.Context, params *ChatParams, opts ...RequestOption) (<-chan StreamChunk, error) {
+	ctx = s.client.withClient(withRequestOptions(ctx, opts))
 	params.Stream = true
+	return s.client.wrapStream(s.stream)(ctx, params)
+}
 
-	jsonData, err := json.Marshal(params)
+func (s *ChatService) stream(ctx context.Context, params *ChatParams) (<-chan StreamChunk, error) {
+	release, err := s.client.Breaker.acquire(ctx, params.Model)
 	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(
-		ctx,
-		"POST",
-		s.client.BaseURL+"/api/ai/chat",
-		bytes.NewReader(jsonData),
-	)
+	ctx, span := s.client.startCall(ctx, "POST", "/api/ai/chat", params)
+	ctx = s.client.withHookParams(ctx, params)
+	fail := func(err error, resp *http.Response) error {
+		release(err)
+		result := CallResult{Err: err}
+		if resp != nil {
+			result.StatusCode = resp.StatusCode
+		}
+		span.End(result)
+		s.client.hookError(ctx, params, resp, err)
+		return err
+	}
+
+	jsonData, err := json.Marshal(params)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, fail(fmt.Errorf("marshal request: %w", err), nil)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("CENCORI_API_KEY", s.client.APIKey)
-	req.Header.Set("Accept", "text/event-stream")
+	header := s.client.headers()
+	header.Set("Accept", "text/event-stream")
+	span.Inject(header)
 
-	resp, err := s.client.httpClient.Do(req) //nolint:bodyclose // Body is closed by the streaming goroutine
+	resp, err := s.client.send(ctx, "POST", "/api/ai/chat", jsonData, header) //nolint:bodyclose // Body is closed by the streaming goroutine
 	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+		return nil, fail(err, nil)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		err := handleError(resp)
 		resp.Body.Close() //nolint:errcheck // Closing the response body; error can be ignored here.
 
-		return nil, err
+		return nil, fail(err, resp)
 	}
 
+	s.client.hookResponse(ctx, params, resp, nil)
 	chunks := make(chan StreamChunk)
+	emit := func(chunk StreamChunk) {
+		s.client.hookChunk(ctx, params, chunk)
+		chunks <- chunk
+	}
 
 	go func() {
+		var streamErr error
+		var result streamResult
 		defer close(chunks)
+		defer func() { release(streamErr) }()
+		defer func() {
+			span.End(result.finish(streamErr))
+			if streamErr != nil {
+				s.client.hookError(ctx, params, resp, streamErr)
+			}
+		}()
 		defer resp.Body.Close() //nolint:errcheck // Closing the response body; error can be ignored here.
 
 		done := make(chan struct{})
@@ -112,6 +166,7 @@ func (s *ChatService) Stream(ctx context.Context, params *ChatParams) (<-chan St
 
 			if err != nil {
 				if ctx.Err() != nil {
+					streamErr = ctx.Err()
 					return
 				}
 
@@ -119,7 +174,8 @@ func (s *ChatService) Stream(ctx context.Context, params *ChatParams) (<-chan St
 					return
 				}
 
-				chunks <- StreamChunk{Err: fmt.Errorf("stream read: %w", err)}
+				streamErr = fmt.Errorf("stream read: %w", err)
+				emit(StreamChunk{Err: streamErr})
 				return
 			}
 
@@ -140,18 +196,21 @@ func (s *ChatService) Stream(ctx context.Context, params *ChatParams) (<-chan St
 				var apiErr APIError
 				if err := json.Unmarshal([]byte(data), &apiErr); err == nil {
 					apiErr.fillSentinel()
-					chunks <- StreamChunk{Err: &apiErr}
+					streamErr = &apiErr
+					emit(StreamChunk{Err: &apiErr})
 					return
 				}
 			}
 
 			var chunk StreamChunk
 			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
-				chunks <- StreamChunk{Err: fmt.Errorf("unmarshal chunk: %w", err)}
+				streamErr = fmt.Errorf("unmarshal chunk: %w", err)
+				emit(StreamChunk{Err: streamErr})
 				return
 			}
 
-			chunks <- chunk
+			result.observe(span, &chunk)
+			emit(chunk)
 		}
 	}()
 
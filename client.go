@@ -2,14 +2,33 @@ package cencori
 
 import (
 	"errors"
+	"log/slog"
 	"net/http"
 	"time"
 )
 
 type ClientOptions struct {
-	APIKey  string
-	BaseURL string
-	Timeout time.Duration
+	APIKey          string
+	BaseURL         string
+	Timeout         time.Duration
+	CircuitBreaker  *BreakerConfig
+	Failover        *FailoverConfig
+	Cache           *CacheConfig
+	Middleware      []Middleware
+	Moderation      *ModerationConfig
+	Tracer          Tracer
+	Logger          *slog.Logger
+	LogBodyBytes    int
+	MetricsRecorder MetricsRecorder
+	Pricing         map[string]Price
+	Hooks           *Hooks
+	Retry           *RetryPolicy
+	Credentials     CredentialsProvider
+	// EnvDefaults loads defaults from the environment and the config file;
+	// see WithEnvDefaults.
+	EnvDefaults bool
+	Profile     string
+	ConfigFile  string
 }
 
 func WithAPIKey(apiKey string) Option {
@@ -24,29 +43,100 @@ func WithTimeout(timeout time.Duration) Option {
 	return func(c *ClientOptions) { c.Timeout = timeout }
 }
 
+// WithBaseURLs configures several base URLs with failover between them.
+// Earlier URLs are preferred; later ones are used when earlier ones are unreachable.
+func WithBaseURLs(urls ...string) Option {
+	return func(c *ClientOptions) {
+		if c.Failover == nil {
+			c.Failover = &FailoverConfig{}
+		}
+		c.Failover.Endpoints = c.Failover.Endpoints[:0]
+		for i, url := range urls {
+			c.Failover.Endpoints = append(c.Failover.Endpoints, Endpoint{URL: url, Priority: i})
+		}
+	}
+}
+
+// WithFailover configures multi-endpoint failover with health checking and
+// priority/latency-based endpoint selection.
+func WithFailover(cfg FailoverConfig) Option {
+	return func(c *ClientOptions) { c.Failover = &cfg }
+}
+
+// WithCache enables the exact-match response cache for Chat.Create and
+// Chat.Embeddings. Responses served from the cache have CacheHit set.
+func WithCache(cfg CacheConfig) Option {
+	return func(c *ClientOptions) { c.Cache = &cfg }
+}
+
+// WithCircuitBreaker enables a client-side circuit breaker that fails fast
+// with a *CircuitOpenError while a model or provider is unhealthy.
+func WithCircuitBreaker(cfg BreakerConfig) Option {
+	return func(c *ClientOptions) { c.CircuitBreaker = &cfg }
+}
+
 type Client struct {
+	// APIKey is the key given to WithAPIKey or loaded by WithEnvDefaults.
+	//
+	// Deprecated: Requests authenticate with the client's credentials
+	// provider, so assigning APIKey after NewClient has no effect. Use
+	// WithCredentials with RotatingCredentials and call
+	// RotatingCredentials.Set to rotate keys.
 	APIKey     string
 	BaseURL    string
 	httpClient *http.Client
 
-	Chat     *ChatService
-	Projects *ProjectsService
-	APIKeys  *APIKeysService
-	Metrics  *MetricsService
+	// Breaker is nil unless WithCircuitBreaker was used.
+	Breaker *CircuitBreaker
+
+	endpoints  *endpointPool
+	cache      *responseCache
+	middleware []Middleware
+	// moderation is the WithModeration check. It is kept out of middleware
+	// and wrapped innermost, so that it screens messages as rewritten by
+	// every middleware, including middleware added later with Use.
+	moderation *Middleware
+	tracer     Tracer
+	logger     *slog.Logger
+	// logBodyBytes is the body logging limit; zero disables body logging.
+	logBodyBytes int
+	metrics      MetricsRecorder
+	pricing      map[string]Price
+	hooks        *Hooks
+	retry        *RetryPolicy
+	credentials  CredentialsProvider
+
+	Chat       *ChatService
+	Projects   *ProjectsService
+	APIKeys    *APIKeysService
+	Metrics    *MetricsService
+	Batches    *BatchesService
+	Moderation *ModerationService
 }
 
 type Option func(*ClientOptions)
 
+// Built-in client defaults.
+const (
+	defaultBaseURL = "https://cencori.com"
+	defaultTimeout = 30 * time.Second
+)
+
 func NewClient(opts ...Option) (*Client, error) {
 	config := &ClientOptions{
-		BaseURL: "https://cencori.com",
-		Timeout: 30 * time.Second,
+		BaseURL: defaultBaseURL,
+		Timeout: defaultTimeout,
 	}
 	for _, opt := range opts {
 		opt(config)
 	}
+	if config.EnvDefaults {
+		if err := config.applyDefaults(); err != nil {
+			return nil, err
+		}
+	}
 
-	if config.APIKey == "" {
+	if config.APIKey == "" && config.Credentials == nil {
 		return nil, errors.New("you need a valid API Key to use this client")
 	}
 
@@ -56,12 +146,66 @@ func NewClient(opts ...Option) (*Client, error) {
 		httpClient: &http.Client{
 			Timeout: config.Timeout,
 		},
+		middleware:  config.Middleware,
+		tracer:      config.Tracer,
+		logger:      config.Logger,
+		metrics:     config.MetricsRecorder,
+		pricing:     config.Pricing,
+		hooks:       config.Hooks,
+		retry:       config.Retry,
+		credentials: config.Credentials,
+	}
+	if c.credentials == nil {
+		c.credentials = StaticCredentials(config.APIKey)
+	}
+	if config.Logger != nil {
+		c.logBodyBytes = config.LogBodyBytes
+	}
+
+	if config.Failover != nil && len(config.Failover.Endpoints) > 0 {
+		c.endpoints = newEndpointPool(*config.Failover)
+		c.BaseURL = c.endpoints.endpoints[0].URL
+		if config.Failover.HealthCheckInterval > 0 {
+			go c.endpoints.healthCheck(c.httpClient)
+		}
+	}
+
+	if config.Cache != nil {
+		c.cache = newResponseCache(*config.Cache)
+	}
+
+	if config.CircuitBreaker != nil {
+		c.Breaker = NewCircuitBreaker(*config.CircuitBreaker)
 	}
 
 	c.Chat = &ChatService{client: c}
 	c.Projects = &ProjectsService{client: c}
 	c.APIKeys = &APIKeysService{client: c}
 	c.Metrics = &MetricsService{client: c}
+	c.Batches = &BatchesService{client: c}
+	c.Moderation = &ModerationService{client: c}
+
+	if config.Moderation != nil {
+		mw := c.Moderation.Middleware(*config.Moderation)
+		c.moderation = &mw
+	}
 
 	return c, nil
 }
+
+// Endpoints reports the health and latency of every configured base URL.
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.endpoints == nil {
+		return []EndpointStatus{{URL: c.BaseURL, Healthy: true}}
+	}
+	return c.endpoints.status()
+}
+
+// Close stops background work such as endpoint health checks.
+// The client must not be used after Close.
+func (c *Client) Close() error {
+	if c.endpoints != nil {
+		c.endpoints.close()
+	}
+	return nil
+}
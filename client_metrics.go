@@ -0,0 +1,197 @@
+package cencori
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	PromptPerMillion     float64
+	CompletionPerMillion float64
+}
+
+// Cost returns the cost of usage at price p.
+func (p Price) Cost(usage Usage) float64 {
+	return (float64(usage.PromptTokens)*p.PromptPerMillion + float64(usage.CompletionTokens)*p.CompletionPerMillion) / 1e6
+}
+
+// CallMetrics describes one completed model call.
+type CallMetrics struct {
+	// Operation is "chat", "embeddings" or "moderation".
+	Operation string
+	Model     string
+	// StatusCode is the HTTP status, or zero if no response was received.
+	StatusCode int
+	Latency    time.Duration
+	// TimeToFirstToken is set for streams that produced content.
+	TimeToFirstToken time.Duration
+	Usage            Usage
+	// Cost is estimated from the prices given to WithPricing; zero without one.
+	Cost float64
+	Err  error
+}
+
+// Status returns the status label for m: the HTTP status code, or "error"
+// when no response was received.
+func (m CallMetrics) Status() string {
+	if m.StatusCode == 0 {
+		return "error"
+	}
+	return strconv.Itoa(m.StatusCode)
+}
+
+// MetricsRecorder receives client-side metrics for model calls. See the
+// promcencori package for a Prometheus implementation and ExpvarMetrics for
+// a dependency-free one. Implementations must be safe for concurrent use.
+type MetricsRecorder interface {
+	// ObserveRequest is called once per completed model call.
+	ObserveRequest(m CallMetrics)
+	// IncRetry is called each time a model call is retried, including
+	// failover to another endpoint.
+	IncRetry(operation, model string)
+	// IncCacheHit is called when a response is served from a cache instead
+	// of the API.
+	IncCacheHit(operation, model string)
+}
+
+// WithMetrics reports client-side metrics for every model call to r.
+func WithMetrics(r MetricsRecorder) Option {
+	return func(c *ClientOptions) { c.MetricsRecorder = r }
+}
+
+// WithPricing sets per-model prices used to estimate the cost reported to
+// the MetricsRecorder.
+func WithPricing(prices map[string]Price) Option {
+	return func(c *ClientOptions) { c.Pricing = prices }
+}
+
+// metricsSpan reports a call to the MetricsRecorder when it ends.
+type metricsSpan struct {
+	next       CallSpan
+	client     *Client
+	info       CallInfo
+	start      time.Time
+	firstToken time.Duration
+}
+
+func (s *metricsSpan) Inject(header http.Header) { s.next.Inject(header) }
+
+func (s *metricsSpan) FirstToken() {
+	s.firstToken = time.Since(s.start)
+	s.next.FirstToken()
+}
+
+func (s *metricsSpan) End(result CallResult) {
+	s.client.metrics.ObserveRequest(CallMetrics{
+		Operation:        s.info.Operation,
+		Model:            s.info.Model,
+		StatusCode:       result.StatusCode,
+		Latency:          time.Since(s.start),
+		TimeToFirstToken: s.firstToken,
+		Usage:            result.Usage,
+		Cost:             s.client.pricing[s.info.Model].Cost(result.Usage),
+		Err:              result.Err,
+	})
+	s.next.End(result)
+}
+
+func (c *Client) recordRetry(operation, model string) {
+	if c.metrics != nil && operation != "" {
+		c.metrics.IncRetry(operation, model)
+	}
+}
+
+func (c *Client) recordCacheHit(operation, model string, hit bool) {
+	if c.metrics != nil && hit {
+		c.metrics.IncCacheHit(operation, model)
+	}
+}
+
+// ExpvarMetrics is a MetricsRecorder that publishes counters through the
+// expvar package, for programs that do not run Prometheus. Values are keyed
+// by "operation/model" (and "/status" for request counts).
+type ExpvarMetrics struct {
+	requests         *expvar.Map
+	errors           *expvar.Map
+	latencySeconds   *expvar.Map
+	ttftSeconds      *expvar.Map
+	streams          *expvar.Map
+	promptTokens     *expvar.Map
+	completionTokens *expvar.Map
+	costUSD          *expvar.Map
+	retries          *expvar.Map
+	cacheHits        *expvar.Map
+}
+
+// expvarMu serializes the lookup and creation of expvar maps so that
+// concurrent calls to NewExpvarMetrics with one prefix share the same maps.
+var expvarMu sync.Mutex
+
+// NewExpvarMetrics publishes the metrics under the expvar name prefix, e.g.
+// "cencori" serves "cencori.requests" at /debug/vars. Calls with the same
+// prefix share the published values. It panics if one of the names is
+// already published as something other than an *expvar.Map.
+func NewExpvarMetrics(prefix string) *ExpvarMetrics {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	return &ExpvarMetrics{
+		requests:         expvarMap(prefix + ".requests"),
+		errors:           expvarMap(prefix + ".errors"),
+		latencySeconds:   expvarMap(prefix + ".latency_seconds_sum"),
+		ttftSeconds:      expvarMap(prefix + ".time_to_first_token_seconds_sum"),
+		streams:          expvarMap(prefix + ".streams_with_first_token"),
+		promptTokens:     expvarMap(prefix + ".prompt_tokens"),
+		completionTokens: expvarMap(prefix + ".completion_tokens"),
+		costUSD:          expvarMap(prefix + ".cost_usd"),
+		retries:          expvarMap(prefix + ".retries"),
+		cacheHits:        expvarMap(prefix + ".cache_hits"),
+	}
+}
+
+// expvarMap returns the map published as name, publishing it if needed.
+// Callers hold expvarMu.
+func expvarMap(name string) *expvar.Map {
+	v := expvar.Get(name)
+	if v == nil {
+		return expvar.NewMap(name)
+	}
+	m, ok := v.(*expvar.Map)
+	if !ok {
+		panic(fmt.Sprintf("cencori: expvar %q is a %T, not a map", name, v))
+	}
+	return m
+}
+
+// ObserveRequest implements MetricsRecorder.
+func (e *ExpvarMetrics) ObserveRequest(m CallMetrics) {
+	key := m.Operation + "/" + m.Model
+	e.requests.Add(key+"/"+m.Status(), 1)
+	if m.Err != nil {
+		e.errors.Add(key, 1)
+	}
+	e.latencySeconds.AddFloat(key, m.Latency.Seconds())
+	if m.TimeToFirstToken > 0 {
+		e.ttftSeconds.AddFloat(key, m.TimeToFirstToken.Seconds())
+		e.streams.Add(key, 1)
+	}
+	e.promptTokens.Add(key, int64(m.Usage.PromptTokens))
+	e.completionTokens.Add(key, int64(m.Usage.CompletionTokens))
+	if m.Cost > 0 {
+		e.costUSD.AddFloat(key, m.Cost)
+	}
+}
+
+// IncRetry implements MetricsRecorder.
+func (e *ExpvarMetrics) IncRetry(operation, model string) {
+	e.retries.Add(operation+"/"+model, 1)
+}
+
+// IncCacheHit implements MetricsRecorder.
+func (e *ExpvarMetrics) IncCacheHit(operation, model string) {
+	e.cacheHits.Add(operation+"/"+model, 1)
+}
@@ -0,0 +1,159 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeRecorder struct {
+	mu        sync.Mutex
+	requests  []CallMetrics
+	retries   []string
+	cacheHits []string
+}
+
+func (f *fakeRecorder) ObserveRequest(m CallMetrics) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, m)
+}
+
+func (f *fakeRecorder) IncRetry(operation, model string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.retries = append(f.retries, operation+"/"+model)
+}
+
+func (f *fakeRecorder) IncCacheHit(operation, model string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cacheHits = append(f.cacheHits, operation+"/"+model)
+}
+
+func TestMetrics_CreateAndCacheHit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-1", Usage: Usage{PromptTokens: 1000, CompletionTokens: 2000}})
+	}))
+	defer server.Close()
+
+	rec := &fakeRecorder{}
+	client, _ := NewClient(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithMetrics(rec),
+		WithCache(CacheConfig{}),
+		WithPricing(map[string]Price{"gpt-4o": {PromptPerMillion: 5, CompletionPerMillion: 15}}),
+	)
+
+	zero := 0.0
+	for range 2 {
+		_, err := client.Chat.Create(context.Background(), &ChatParams{
+			Model:       "gpt-4o",
+			Temperature: &zero,
+			Messages:    []Message{{Role: "user", Content: "Hi"}},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if len(rec.requests) != 1 {
+		t.Fatalf("expected 1 observed request, got %d", len(rec.requests))
+	}
+	m := rec.requests[0]
+	if m.Operation != "chat" || m.Model != "gpt-4o" || m.Status() != "200" {
+		t.Errorf("unexpected labels: %+v", m)
+	}
+	if m.Usage.CompletionTokens != 2000 {
+		t.Errorf("expected usage to be recorded, got %+v", m.Usage)
+	}
+	if want := 0.035; m.Cost < want-1e-9 || m.Cost > want+1e-9 {
+		t.Errorf("expected cost %v, got %v", want, m.Cost)
+	}
+	if len(rec.cacheHits) != 1 || rec.cacheHits[0] != "chat/gpt-4o" {
+		t.Errorf("expected one chat cache hit, got %v", rec.cacheHits)
+	}
+}
+
+func TestMetrics_ErrorAndFailoverRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"bad model","code":"INVALID_MODEL"}`))
+	}))
+	defer server.Close()
+
+	rec := &fakeRecorder{}
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURLs(unreachableURL(), server.URL), WithMetrics(rec))
+	defer client.Close()
+
+	_, err := client.Chat.Create(context.Background(), &ChatParams{Model: "bad", Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if len(rec.retries) != 1 || rec.retries[0] != "chat/bad" {
+		t.Errorf("expected one chat retry, got %v", rec.retries)
+	}
+	if len(rec.requests) != 1 || rec.requests[0].Status() != "400" || rec.requests[0].Err == nil {
+		t.Errorf("expected one failed request with status 400, got %+v", rec.requests)
+	}
+}
+
+func TestMetrics_StreamTimeToFirstToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	rec := &fakeRecorder{}
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithMetrics(rec))
+
+	chunks, err := client.Chat.Stream(context.Background(), &ChatParams{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for range chunks {
+	}
+
+	if len(rec.requests) != 1 {
+		t.Fatalf("expected 1 observed request, got %d", len(rec.requests))
+	}
+	m := rec.requests[0]
+	if m.TimeToFirstToken <= 0 || m.TimeToFirstToken > m.Latency {
+		t.Errorf("expected time to first token within latency, got %v of %v", m.TimeToFirstToken, m.Latency)
+	}
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	prefix := fmt.Sprintf("cencori_test_%d", time.Now().UnixNano())
+	e := NewExpvarMetrics(prefix)
+	e.ObserveRequest(CallMetrics{Operation: "chat", Model: "gpt-4o", StatusCode: 200, Usage: Usage{PromptTokens: 3}})
+	e.ObserveRequest(CallMetrics{Operation: "chat", Model: "gpt-4o", Err: context.DeadlineExceeded})
+	e.IncRetry("chat", "gpt-4o")
+
+	if got := e.requests.Get("chat/gpt-4o/200").String(); got != "1" {
+		t.Errorf("expected 1 successful request, got %s", got)
+	}
+	if got := e.requests.Get("chat/gpt-4o/error").String(); got != "1" {
+		t.Errorf("expected 1 failed request, got %s", got)
+	}
+	if got := e.promptTokens.Get("chat/gpt-4o").String(); got != "3" {
+		t.Errorf("expected 3 prompt tokens, got %s", got)
+	}
+	if got := e.retries.Get("chat/gpt-4o").String(); got != "1" {
+		t.Errorf("expected 1 retry, got %s", got)
+	}
+
+	if again := NewExpvarMetrics(prefix); again.requests != e.requests {
+		t.Error("expected a reused prefix to share the published maps")
+	}
+}
@@ -0,0 +1,263 @@
+package cencori
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by WithEnvDefaults and WithProfile.
+const (
+	envAPIKey     = "CENCORI_API_KEY"
+	envBaseURL    = "CENCORI_BASE_URL"
+	envTimeout    = "CENCORI_TIMEOUT"
+	envProfile    = "CENCORI_PROFILE"
+	envConfigFile = "CENCORI_CONFIG_FILE"
+)
+
+// profile is a named set of client defaults from the config file.
+type profile struct {
+	APIKey  string
+	BaseURL string
+	Timeout time.Duration
+}
+
+// WithEnvDefaults loads defaults from the environment and the config file.
+// Options passed to NewClient take precedence over environment variables,
+// which take precedence over the profile, which takes precedence over the
+// built-in defaults:
+//
+//   - CENCORI_API_KEY, CENCORI_BASE_URL and CENCORI_TIMEOUT ("30s" or seconds)
+//   - the profile named by CENCORI_PROFILE, or "default", in the config file
+//     at CENCORI_CONFIG_FILE or $XDG_CONFIG_HOME/cencori/config.toml
+//     (~/.config/cencori/config.toml)
+//
+// The config file has one table per profile:
+//
+//	[default]
+//	api_key = "csk_..."
+//
+//	[acme-staging]
+//	api_key  = "csk_..."
+//	base_url = "https://staging.cencori.com"
+//	timeout  = "1m"
+func WithEnvDefaults() Option {
+	return func(c *ClientOptions) { c.EnvDefaults = true }
+}
+
+// WithProfile is WithEnvDefaults using the named profile, which must exist.
+func WithProfile(name string) Option {
+	return func(c *ClientOptions) {
+		c.EnvDefaults = true
+		c.Profile = name
+	}
+}
+
+// WithConfigFile is WithEnvDefaults reading profiles from path, which must exist.
+func WithConfigFile(path string) Option {
+	return func(c *ClientOptions) {
+		c.EnvDefaults = true
+		c.ConfigFile = path
+	}
+}
+
+// applyDefaults fills the fields that the options left at their built-in
+// defaults from the environment and then the config file profile.
+func (c *ClientOptions) applyDefaults() error {
+	d, err := c.loadDefaults()
+	if err != nil {
+		return err
+	}
+	if c.APIKey == "" {
+		c.APIKey = d.APIKey
+	}
+	if c.BaseURL == defaultBaseURL && d.BaseURL != "" {
+		c.BaseURL = d.BaseURL
+	}
+	if c.Timeout == defaultTimeout && d.Timeout > 0 {
+		c.Timeout = d.Timeout
+	}
+	return nil
+}
+
+// loadDefaults reads the profile selected by c and then the environment,
+// which takes precedence.
+func (c *ClientOptions) loadDefaults() (*ClientOptions, error) {
+	d := &ClientOptions{}
+	name, required := c.Profile, c.Profile != ""
+	if name == "" {
+		name, required = os.Getenv(envProfile), os.Getenv(envProfile) != ""
+	}
+	if name == "" {
+		name = "default"
+	}
+
+	path, pathGiven := c.ConfigFile, c.ConfigFile != ""
+	if path == "" {
+		path, pathGiven = os.Getenv(envConfigFile), os.Getenv(envConfigFile) != ""
+	}
+	if path == "" {
+		path = defaultConfigFile()
+	}
+
+	profiles, err := readProfiles(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !pathGiven && !required:
+	case err != nil:
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	if p, ok := profiles[name]; ok {
+		d.setIfPresent(p.APIKey, p.BaseURL, p.Timeout)
+	} else if required {
+		return nil, fmt.Errorf("cencori: profile %q not found in %s", name, path)
+	}
+
+	var timeout time.Duration
+	if v := os.Getenv(envTimeout); v != "" {
+		timeout, err = parseTimeout(v)
+		if err != nil {
+			return nil, fmt.Errorf("cencori: invalid %s: %w", envTimeout, err)
+		}
+	}
+	d.setIfPresent(os.Getenv(envAPIKey), os.Getenv(envBaseURL), timeout)
+	return d, nil
+}
+
+func (c *ClientOptions) setIfPresent(apiKey, baseURL string, timeout time.Duration) {
+	if apiKey != "" {
+		c.APIKey = apiKey
+	}
+	if baseURL != "" {
+		c.BaseURL = baseURL
+	}
+	if timeout > 0 {
+		c.Timeout = timeout
+	}
+}
+
+// defaultConfigFile returns $XDG_CONFIG_HOME/cencori/config.toml, falling
+// back to ~/.config on every platform.
+func defaultConfigFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "cencori", "config.toml")
+}
+
+// parseTimeout accepts a Go duration such as "90s" or a number of seconds.
+func parseTimeout(v string) (time.Duration, error) {
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	return time.ParseDuration(v)
+}
+
+func readProfiles(path string) (map[string]profile, error) {
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck // Read-only file.
+
+	profiles, err := parseProfiles(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return profiles, nil
+}
+
+// parseProfiles parses the subset of TOML used by config files: tables of
+// string or number values, with comments.
+func parseProfiles(r io.Reader) (map[string]profile, error) {
+	profiles := make(map[string]profile)
+	var name string
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid table header", n)
+			}
+			name = unquote(strings.TrimSpace(line[1 : len(line)-1]))
+			if _, ok := profiles[name]; !ok {
+				profiles[name] = profile{}
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("line %d: key outside of a profile table", n)
+		}
+		key, value = strings.TrimSpace(key), unquote(strings.TrimSpace(value))
+
+		p := profiles[name]
+		switch key {
+		case "api_key":
+			p.APIKey = value
+		case "base_url":
+			p.BaseURL = value
+		case "timeout":
+			d, err := parseTimeout(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid timeout: %w", n, err)
+			}
+			p.Timeout = d
+		}
+		profiles[name] = p
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// stripComment removes a # comment that is not inside a quoted string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case quote != 0 && ch == quote:
+			quote = 0
+		case quote == 0 && (ch == '"' || ch == '\''):
+			quote = ch
+		case quote == 0 && ch == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		if s[0] == '"' {
+			if u, err := strconv.Unquote(s); err == nil {
+				return u
+			}
+		}
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package cencori
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfig = `# cencori profiles
+[default]
+api_key = "file-default-key"
+timeout = "45s"
+
+[acme-staging]
+api_key  = 'file-staging-key' # inline comment
+base_url = "https://staging.example.com#edge"
+timeout  = 90
+`
+
+// useConfigHome points the default config file at a temporary directory
+// holding content and clears the cencori environment variables.
+func useConfigHome(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{envAPIKey, envBaseURL, envTimeout, envProfile, envConfigFile} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, "cencori", "config.toml")
+	if content != "" {
+		os.MkdirAll(filepath.Dir(path), 0o700)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestEnvDefaults_Precedence(t *testing.T) {
+	useConfigHome(t, testConfig)
+
+	client, err := NewClient(WithEnvDefaults())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if client.APIKey != "file-default-key" || client.httpClient.Timeout != 45*time.Second || client.BaseURL != "https://cencori.com" {
+		t.Errorf("expected the default profile over built-in defaults, got key %q, timeout %v, base URL %q",
+			client.APIKey, client.httpClient.Timeout, client.BaseURL)
+	}
+
+	t.Setenv(envAPIKey, "env-key")
+	t.Setenv(envTimeout, "2m")
+	client, _ = NewClient(WithEnvDefaults())
+	if client.APIKey != "env-key" || client.httpClient.Timeout != 2*time.Minute {
+		t.Errorf("expected the environment over the profile, got key %q, timeout %v", client.APIKey, client.httpClient.Timeout)
+	}
+
+	client, _ = NewClient(WithAPIKey("explicit-key"), WithEnvDefaults())
+	if client.APIKey != "explicit-key" {
+		t.Errorf("expected explicit options to win regardless of order, got %q", client.APIKey)
+	}
+}
+
+func TestEnvDefaults_OptionsRunOnce(t *testing.T) {
+	useConfigHome(t, testConfig)
+
+	var calls int
+	counting := func(*ClientOptions) { calls++ }
+	if _, err := NewClient(WithEnvDefaults(), counting); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected options to be applied once, got %d", calls)
+	}
+}
+
+func TestEnvDefaults_NamedProfile(t *testing.T) {
+	useConfigHome(t, testConfig)
+
+	client, err := NewClient(WithProfile("acme-staging"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if client.APIKey != "file-staging-key" || client.BaseURL != "https://staging.example.com#edge" || client.httpClient.Timeout != 90*time.Second {
+		t.Errorf("unexpected staging profile: key %q, base URL %q, timeout %v", client.APIKey, client.BaseURL, client.httpClient.Timeout)
+	}
+
+	t.Setenv(envProfile, "acme-staging")
+	client, _ = NewClient(WithEnvDefaults())
+	if client.APIKey != "file-staging-key" {
+		t.Errorf("expected CENCORI_PROFILE to select the profile, got %q", client.APIKey)
+	}
+
+	if _, err := NewClient(WithProfile("missing")); err == nil || !strings.Contains(err.Error(), `profile "missing" not found`) {
+		t.Errorf("expected a missing profile error, got %v", err)
+	}
+}
+
+func TestEnvDefaults_NoConfigFile(t *testing.T) {
+	useConfigHome(t, "")
+
+	if _, err := NewClient(WithEnvDefaults()); err == nil {
+		t.Error("expected an error without any API key")
+	}
+
+	t.Setenv(envAPIKey, "env-key")
+	client, err := NewClient(WithEnvDefaults())
+	if err != nil || client.APIKey != "env-key" {
+		t.Fatalf("expected the key from the environment, got %v", err)
+	}
+
+	if _, err := NewClient(WithConfigFile(filepath.Join(t.TempDir(), "missing.toml"))); err == nil {
+		t.Error("expected an error for an explicit missing config file")
+	}
+}
+
+func TestEnvDefaults_Invalid(t *testing.T) {
+	useConfigHome(t, "[default]\ntimeout = \"soon\"\n")
+	if _, err := NewClient(WithEnvDefaults()); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected a parse error with the line number, got %v", err)
+	}
+
+	useConfigHome(t, "")
+	t.Setenv(envAPIKey, "env-key")
+	t.Setenv(envTimeout, "soon")
+	if _, err := NewClient(WithEnvDefaults()); err == nil {
+		t.Error("expected an error for an invalid CENCORI_TIMEOUT")
+	}
+}
+
+func TestNewClient_IgnoresEnvironmentByDefault(t *testing.T) {
+	useConfigHome(t, testConfig)
+	t.Setenv(envAPIKey, "env-key")
+
+	if _, err := NewClient(); err == nil {
+		t.Error("expected NewClient without WithEnvDefaults to require an API key")
+	}
+}
@@ -231,14 +231,14 @@ func TestEmbeddings_SingleString(t *testing.T) {
 			t.Errorf("expected path /api/v1/embeddings, got %s", r.URL.Path)
 		}
 
-		var req EmbeddingParams
+		var req map[string]any
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			t.Fatalf("failed to decode request: %v", err)
 		}
 
-		// Verify input is correct
-		if req.Input != "Hello world" {
-			t.Errorf("expected input 'Hello world', got %v", req.Input)
+		// Verify a single input is still sent as a plain string
+		if req["input"] != "Hello world" {
+			t.Errorf("expected input 'Hello world', got %v", req["input"])
 		}
 
 		// Return embedding response
@@ -247,7 +247,7 @@ func TestEmbeddings_SingleString(t *testing.T) {
 			Object: "list",
 			Data: []EmbeddingData{
 				{
-					Embedding: []float64{0.1, 0.2, 0.3},
+					Embedding: []float32{0.1, 0.2, 0.3},
 					Index:     0,
 				},
 			},
@@ -262,7 +262,7 @@ func TestEmbeddings_SingleString(t *testing.T) {
 	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
 
 	resp, err := client.Chat.Embeddings(context.Background(), EmbeddingParams{
-		Input: "Hello world",
+		Input: EmbeddingText("Hello world"),
 		Model: "text-embedding-3-small",
 	})
 
@@ -282,13 +282,13 @@ func TestEmbeddings_SingleString(t *testing.T) {
 
 func TestEmbeddings_MultipleStrings(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var req EmbeddingParams
+		var req map[string]any
 		json.NewDecoder(r.Body).Decode(&req)
 
 		// Verify input is array
-		inputs, ok := req.Input.([]interface{})
+		inputs, ok := req["input"].([]interface{})
 		if !ok {
-			t.Fatalf("expected input to be array, got %T", req.Input)
+			t.Fatalf("expected input to be array, got %T", req["input"])
 		}
 		if len(inputs) != 2 {
 			t.Errorf("expected 2 inputs, got %d", len(inputs))
@@ -298,8 +298,8 @@ func TestEmbeddings_MultipleStrings(t *testing.T) {
 		resp := EmbeddingResponse{
 			Model: "text-embedding-3-small",
 			Data: []EmbeddingData{
-				{Embedding: []float64{0.1, 0.2}, Index: 0},
-				{Embedding: []float64{0.3, 0.4}, Index: 1},
+				{Embedding: []float32{0.1, 0.2}, Index: 0},
+				{Embedding: []float32{0.3, 0.4}, Index: 1},
 			},
 			Usage: EmbeddingUsage{TotalTokens: 4},
 		}
@@ -310,7 +310,7 @@ func TestEmbeddings_MultipleStrings(t *testing.T) {
 	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
 
 	resp, err := client.Chat.Embeddings(context.Background(), EmbeddingParams{
-		Input: []string{"First text", "Second text"},
+		Input: EmbeddingTexts([]string{"First text", "Second text"}),
 		Model: "text-embedding-3-small",
 	})
 
@@ -335,7 +335,7 @@ func TestEmbeddings_ErrorHandling(t *testing.T) {
 	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
 
 	_, err := client.Chat.Embeddings(context.Background(), EmbeddingParams{
-		Input: "test",
+		Input: EmbeddingText("test"),
 		Model: "invalid-model",
 	})
 
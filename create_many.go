@@ -0,0 +1,201 @@
+package cencori
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter paces outgoing requests. Wait blocks until a request may be sent or
+// ctx is done. *RateLimiter and golang.org/x/time/rate.Limiter satisfy it.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// RateLimiter is a token bucket Limiter. Share one between calls to keep
+// their combined request rate under a provider quota.
+type RateLimiter struct {
+	rate  float64 // tokens per second
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter allows perSecond requests per second on average with bursts
+// of up to burst requests.
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{rate: perSecond, burst: float64(burst), tokens: float64(burst), now: time.Now}
+}
+
+// Wait implements Limiter.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token if one is available and otherwise returns how long
+// until the next one is.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	if l.rate <= 0 {
+		return time.Hour
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// CreateManyParams configures ChatService.CreateMany.
+type CreateManyParams struct {
+	Requests []ChatParams
+	// Concurrency is the number of requests in flight at once (default 4).
+	Concurrency int
+	// Limiter, if set, is waited on before each request is sent.
+	Limiter Limiter
+	// FailFast cancels the remaining requests on the first failure and returns
+	// its error. By default every request runs and failures are reported per item.
+	FailFast bool
+}
+
+// ChatResult is the outcome of one request sent by CreateMany.
+type ChatResult struct {
+	Response *ChatResponse
+	Err      error
+	Latency  time.Duration
+}
+
+// CreateManyResponse holds the results of CreateMany in request order.
+type CreateManyResponse struct {
+	Results []ChatResult
+	// Usage is summed across the successful responses.
+	Usage Usage
+	// Failed is the number of requests that returned an error.
+	Failed int
+}
+
+// CreateMany sends many chat requests concurrently through Create. Results
+// are in the order of params.Requests. In best-effort mode (the default) the
+// returned error is only set when ctx is done; with FailFast the first failure
+// cancels the requests still pending and is returned. The response is
+// returned alongside the error with the results collected so far; requests
+// that were never sent fail with the cancellation error.
+//
+// A key given with WithIdempotencyKey is suffixed with "-<index>" for each
+// request, so every request stays idempotent on its own.
+func (s *ChatService) CreateMany(ctx context.Context, params CreateManyParams, opts ...RequestOption) (*CreateManyResponse, error) {
+	parent := withRequestOptions(ctx, opts)
+	if params.Concurrency <= 0 {
+		params.Concurrency = 4
+	}
+	var idempotencyKey string
+	if o := requestOptionsFrom(parent); o != nil {
+		idempotencyKey = o.idempotencyKey
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		result   = &CreateManyResponse{Results: make([]ChatResult, len(params.Requests))}
+	)
+
+	sent := make([]bool, len(params.Requests))
+	sem := make(chan struct{}, params.Concurrency)
+	var wg sync.WaitGroup
+	for i := range params.Requests {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		sent[i] = true
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			itemCtx := ctx
+			if idempotencyKey != "" {
+				itemCtx = withRequestOptions(ctx, []RequestOption{WithIdempotencyKey(fmt.Sprintf("%s-%d", idempotencyKey, i))})
+			}
+			res := s.createOne(itemCtx, params.Limiter, params.Requests[i])
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Results[i] = res
+			if res.Err != nil {
+				result.Failed++
+				if params.FailFast && firstErr == nil {
+					firstErr = fmt.Errorf("request %d: %w", i, res.Err)
+					cancel()
+				}
+				return
+			}
+			result.Usage.PromptTokens += res.Response.Usage.PromptTokens
+			result.Usage.CompletionTokens += res.Response.Usage.CompletionTokens
+			result.Usage.TotalTokens += res.Response.Usage.TotalTokens
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range result.Results {
+		if !sent[i] {
+			result.Results[i].Err = ctx.Err()
+			result.Failed++
+		}
+	}
+
+	if firstErr != nil {
+		return result, firstErr
+	}
+	if err := parent.Err(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (s *ChatService) createOne(ctx context.Context, limiter Limiter, params ChatParams) ChatResult {
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return ChatResult{Err: err}
+		}
+	}
+	start := time.Now()
+	resp, err := s.Create(ctx, &params)
+	return ChatResult{Response: resp, Err: err, Latency: time.Since(start)}
+}
@@ -0,0 +1,155 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// chatEchoServer answers each chat request with an ID of "chat-<content>" and
+// fails requests whose content is "fail".
+func chatEchoServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var params ChatParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		content := params.Messages[0].Content
+		if content == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "bad model", "code": "INVALID_MODEL"})
+			return
+		}
+		json.NewEncoder(w).Encode(ChatResponse{
+			ID:    "chat-" + content,
+			Usage: Usage{PromptTokens: 2, CompletionTokens: 3, TotalTokens: 5},
+		})
+	}))
+}
+
+func userRequests(contents ...string) []ChatParams {
+	reqs := make([]ChatParams, len(contents))
+	for i, c := range contents {
+		reqs[i] = ChatParams{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: c}}}
+	}
+	return reqs
+}
+
+func TestCreateMany_BestEffort(t *testing.T) {
+	var calls atomic.Int32
+	server := chatEchoServer(t, &calls)
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	resp, err := client.Chat.CreateMany(context.Background(), CreateManyParams{
+		Requests:    userRequests("a", "fail", "b", "c"),
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for i, want := range []string{"chat-a", "", "chat-b", "chat-c"} {
+		r := resp.Results[i]
+		if want == "" {
+			if !errors.Is(r.Err, ErrInvalidModel) {
+				t.Errorf("result %d: expected ErrInvalidModel, got %v", i, r.Err)
+			}
+			continue
+		}
+		if r.Err != nil || r.Response.ID != want {
+			t.Errorf("result %d: expected %s, got %+v", i, want, r)
+		}
+	}
+	if resp.Failed != 1 {
+		t.Errorf("expected 1 failure, got %d", resp.Failed)
+	}
+	if resp.Usage != (Usage{PromptTokens: 6, CompletionTokens: 9, TotalTokens: 15}) {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestCreateMany_FailFast(t *testing.T) {
+	var calls atomic.Int32
+	server := chatEchoServer(t, &calls)
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	resp, err := client.Chat.CreateMany(context.Background(), CreateManyParams{
+		Requests:    userRequests("a", "fail", "b", "c", "d", "e"),
+		Concurrency: 1,
+		FailFast:    true,
+	})
+	if !errors.Is(err, ErrInvalidModel) {
+		t.Fatalf("expected ErrInvalidModel, got %v", err)
+	}
+	if calls.Load() > 3 {
+		t.Errorf("expected remaining requests to be cancelled, got %d calls", calls.Load())
+	}
+
+	if resp == nil || resp.Results[0].Response == nil || resp.Results[0].Response.ID != "chat-a" {
+		t.Fatalf("expected the completed result to be kept, got %+v", resp)
+	}
+	if last := resp.Results[5]; !errors.Is(last.Err, context.Canceled) {
+		t.Errorf("expected the unsent request to fail with context.Canceled, got %v", last.Err)
+	}
+	if resp.Failed != 5 {
+		t.Errorf("expected 5 failures, got %d", resp.Failed)
+	}
+}
+
+func TestCreateMany_IdempotencyKeyPerRequest(t *testing.T) {
+	var mu sync.Mutex
+	keys := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params ChatParams
+		json.NewDecoder(r.Body).Decode(&params)
+		mu.Lock()
+		keys[params.Messages[0].Content] = r.Header.Get("Idempotency-Key")
+		mu.Unlock()
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-1"})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	_, err := client.Chat.CreateMany(context.Background(), CreateManyParams{
+		Requests: userRequests("a", "b"),
+	}, WithIdempotencyKey("job-7"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if keys["a"] != "job-7-0" || keys["b"] != "job-7-1" {
+		t.Errorf("expected a key per request, got %v", keys)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	l := NewRateLimiter(2, 2)
+	l.now = func() time.Time { return now }
+
+	if l.reserve() != 0 || l.reserve() != 0 {
+		t.Fatal("expected the burst to be available immediately")
+	}
+	if d := l.reserve(); d != 500*time.Millisecond {
+		t.Errorf("expected a 500ms wait, got %v", d)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("expected a token after 500ms, got wait %v", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package cencori
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoCredentials is returned when a CredentialsProvider has no API key to offer.
+var ErrNoCredentials = errors.New("cencori: no API key available")
+
+// CredentialsProvider supplies the API key for each request. It is consulted
+// on every request, so rotating the key it returns takes effect without
+// recreating the client. Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// WithCredentials authenticates requests with the key returned by p, which
+// takes precedence over WithAPIKey.
+func WithCredentials(p CredentialsProvider) Option {
+	return func(c *ClientOptions) { c.Credentials = p }
+}
+
+// StaticCredentials always returns the same key.
+type StaticCredentials string
+
+// APIKey implements CredentialsProvider.
+func (s StaticCredentials) APIKey(context.Context) (string, error) {
+	if s == "" {
+		return "", ErrNoCredentials
+	}
+	return string(s), nil
+}
+
+// CredentialsFunc adapts a function, such as a lookup in a secrets manager,
+// to a CredentialsProvider. Wrap slow lookups with CacheCredentials.
+type CredentialsFunc func(ctx context.Context) (string, error)
+
+// APIKey implements CredentialsProvider.
+func (f CredentialsFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// RotatingCredentials holds a key that can be replaced while requests are
+// in flight. Requests started after Set use the new key.
+type RotatingCredentials struct {
+	key atomic.Pointer[string]
+}
+
+// NewRotatingCredentials returns RotatingCredentials holding key.
+func NewRotatingCredentials(key string) *RotatingCredentials {
+	r := &RotatingCredentials{}
+	r.Set(key)
+	return r
+}
+
+// Set replaces the key.
+func (r *RotatingCredentials) Set(key string) {
+	r.key.Store(&key)
+}
+
+// APIKey implements CredentialsProvider.
+func (r *RotatingCredentials) APIKey(context.Context) (string, error) {
+	if key := r.key.Load(); key != nil && *key != "" {
+		return *key, nil
+	}
+	return "", ErrNoCredentials
+}
+
+// EnvCredentials reads the key from the named environment variable on
+// every request.
+type EnvCredentials string
+
+// APIKey implements CredentialsProvider.
+func (e EnvCredentials) APIKey(context.Context) (string, error) {
+	if key := os.Getenv(string(e)); key != "" {
+		return key, nil
+	}
+	return "", fmt.Errorf("%w: %s is not set", ErrNoCredentials, string(e))
+}
+
+// FileCredentials reads the key from a file, such as a mounted Kubernetes
+// secret, and reloads it when the file changes.
+type FileCredentials struct {
+	path     string
+	interval time.Duration
+
+	mu        sync.Mutex
+	key       string
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewFileCredentials returns FileCredentials for path. The file is checked
+// for changes at most once per interval (default 10s); surrounding
+// whitespace in the file is ignored.
+func NewFileCredentials(path string, interval time.Duration) *FileCredentials {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &FileCredentials{path: path, interval: interval}
+}
+
+// APIKey implements CredentialsProvider. If the file cannot be read after
+// a key was loaded, the last key is kept.
+func (f *FileCredentials) APIKey(context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.key != "" && time.Since(f.checkedAt) < f.interval {
+		return f.key, nil
+	}
+	f.checkedAt = time.Now()
+
+	info, err := os.Stat(f.path)
+	if err == nil && f.key != "" && info.ModTime().Equal(f.modTime) {
+		return f.key, nil
+	}
+	var data []byte
+	if err == nil {
+		data, err = os.ReadFile(f.path)
+	}
+	if err != nil {
+		if f.key != "" {
+			return f.key, nil
+		}
+		return "", fmt.Errorf("read credentials: %w", err)
+	}
+
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		if f.key != "" {
+			return f.key, nil
+		}
+		return "", fmt.Errorf("%w: %s is empty", ErrNoCredentials, f.path)
+	}
+	f.key, f.modTime = key, info.ModTime()
+	return f.key, nil
+}
+
+// CacheCredentials returns a provider that calls p at most once per ttl and
+// returns the cached key in between. Failed lookups are not cached.
+func CacheCredentials(p CredentialsProvider, ttl time.Duration) CredentialsProvider {
+	return &cachedCredentials{next: p, ttl: ttl}
+}
+
+type cachedCredentials struct {
+	next CredentialsProvider
+	ttl  time.Duration
+
+	mu        sync.Mutex
+	key       string
+	fetchedAt time.Time
+}
+
+func (c *cachedCredentials) APIKey(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.key != "" && time.Since(c.fetchedAt) < c.ttl {
+		return c.key, nil
+	}
+	key, err := c.next.APIKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.key, c.fetchedAt = key, time.Now()
+	return key, nil
+}
+
+// apiKey returns the key for a request: the WithRequestAPIKey override,
+// then the credentials provider. NewClient always configures a provider;
+// Client.APIKey is only used by clients constructed without NewClient. The
+// provider is not consulted for calls that carry their own key.
+func (c *Client) apiKey(ctx context.Context) (string, error) {
+	if o := requestOptionsFrom(ctx); o != nil && o.apiKey != "" {
+		return o.apiKey, nil
+	}
+	if c.credentials == nil {
+		return c.APIKey, nil
+	}
+	key, err := c.credentials.APIKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get credentials: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,142 @@
+package cencori
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotatingCredentials_ConcurrentRotation(t *testing.T) {
+	valid := map[string]bool{"key-a": true, "key-b": true}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !valid[r.Header.Get("CENCORI_API_KEY")] {
+			t.Errorf("unexpected key %q", r.Header.Get("CENCORI_API_KEY"))
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	creds := NewRotatingCredentials("key-a")
+	client, err := NewClient(WithCredentials(creds), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			if i == 10 {
+				creds.Set("key-b")
+			}
+			if _, err := client.Metrics.Get(context.Background(), "24h"); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if key, _ := creds.APIKey(context.Background()); key != "key-b" {
+		t.Errorf("expected the rotated key, got %q", key)
+	}
+}
+
+func TestCredentials_ProviderError(t *testing.T) {
+	client, _ := NewClient(WithCredentials(EnvCredentials("CENCORI_TEST_UNSET_KEY")), WithBaseURL(unreachableURL()))
+
+	_, err := client.Metrics.Get(context.Background(), "24h")
+	if !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestCredentials_RequestAPIKeySkipsProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("CENCORI_API_KEY"); got != "call-key" {
+			t.Errorf("expected the per-call key, got %q", got)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	failing := CredentialsFunc(func(context.Context) (string, error) {
+		t.Error("expected the provider not to be consulted")
+		return "", ErrNoCredentials
+	})
+	client, _ := NewClient(WithCredentials(failing), WithBaseURL(server.URL))
+
+	if _, err := client.Metrics.Get(context.Background(), "24h", WithRequestAPIKey("call-key")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestCredentials_APIKeyFieldIsSnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("CENCORI_API_KEY"); got != "key-a" {
+			t.Errorf("expected the key given to NewClient, got %q", got)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("key-a"), WithBaseURL(server.URL))
+	client.APIKey = "key-b"
+	if _, err := client.Metrics.Get(context.Background(), "24h"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("CENCORI_TEST_KEY", "env-key")
+	if key, err := EnvCredentials("CENCORI_TEST_KEY").APIKey(context.Background()); err != nil || key != "env-key" {
+		t.Errorf("expected env-key, got %q, %v", key, err)
+	}
+}
+
+func TestFileCredentials_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("file-key-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds := NewFileCredentials(path, time.Nanosecond)
+	if key, err := creds.APIKey(context.Background()); err != nil || key != "file-key-1" {
+		t.Fatalf("expected file-key-1, got %q, %v", key, err)
+	}
+
+	if err := os.WriteFile(path, []byte("file-key-2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(path, future, future)
+	if key, _ := creds.APIKey(context.Background()); key != "file-key-2" {
+		t.Errorf("expected the reloaded key, got %q", key)
+	}
+
+	os.Remove(path)
+	if key, err := creds.APIKey(context.Background()); err != nil || key != "file-key-2" {
+		t.Errorf("expected the last key to be kept, got %q, %v", key, err)
+	}
+}
+
+func TestCacheCredentials(t *testing.T) {
+	var calls int
+	creds := CacheCredentials(CredentialsFunc(func(context.Context) (string, error) {
+		calls++
+		return "secret", nil
+	}), time.Hour)
+
+	for range 3 {
+		if key, _ := creds.APIKey(context.Background()); key != "secret" {
+			t.Fatalf("expected secret, got %q", key)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 lookup, got %d", calls)
+	}
+}
@@ -0,0 +1,172 @@
+package cencori
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// EmbedManyParams configures ChatService.EmbedMany.
+type EmbedManyParams struct {
+	Model  string
+	Inputs []string
+	// Dimensions, EncodingFormat and User are sent with every batch; see
+	// EmbeddingParams.
+	Dimensions     *int
+	EncodingFormat EncodingFormat
+	User           *string
+	// BatchSize caps the number of inputs sent in one request (default 256).
+	BatchSize int
+	// MaxBatchTokens caps the estimated tokens sent in one request (default 100000).
+	// An input larger than the limit is sent in a batch of its own.
+	MaxBatchTokens int
+	// Concurrency is the number of batches in flight at once (default 4).
+	Concurrency int
+	// TokenCounter estimates the tokens in an input (default: one token per four bytes).
+	TokenCounter func(string) int
+}
+
+func (p *EmbedManyParams) setDefaults() {
+	if p.BatchSize <= 0 {
+		p.BatchSize = 256
+	}
+	if p.MaxBatchTokens <= 0 {
+		p.MaxBatchTokens = 100_000
+	}
+	if p.Concurrency <= 0 {
+		p.Concurrency = 4
+	}
+	if p.TokenCounter == nil {
+		p.TokenCounter = estimateTokens
+	}
+}
+
+// estimateTokens is a provider-agnostic approximation of the token count of s.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+type embedBatch struct {
+	start  int
+	inputs []string
+}
+
+// splitBatches groups consecutive inputs so that no batch exceeds the count
+// or token limits.
+func splitBatches(params *EmbedManyParams) []embedBatch {
+	var batches []embedBatch
+	cur := embedBatch{}
+	tokens := 0
+	for i, input := range params.Inputs {
+		n := params.TokenCounter(input)
+		if len(cur.inputs) > 0 && (len(cur.inputs) >= params.BatchSize || tokens+n > params.MaxBatchTokens) {
+			batches = append(batches, cur)
+			cur, tokens = embedBatch{}, 0
+		}
+		if len(cur.inputs) == 0 {
+			cur.start = i
+		}
+		cur.inputs = append(cur.inputs, input)
+		tokens += n
+	}
+	if len(cur.inputs) > 0 {
+		batches = append(batches, cur)
+	}
+	return batches
+}
+
+// EmbedMany embeds any number of inputs by splitting them into batches sized
+// for the provider, running the batches concurrently, and reassembling the
+// results. The returned Data is in the order of params.Inputs, with Index set
+// to the input position, and Usage is summed across batches. Batches are
+// retried according to the client's RetryPolicy; the first batch that still
+// fails cancels the remaining batches and its error is returned.
+func (s *ChatService) EmbedMany(ctx context.Context, params EmbedManyParams, opts ...RequestOption) (*EmbeddingResponse, error) {
+	ctx = withRequestOptions(ctx, opts)
+	params.setDefaults()
+	if len(params.Inputs) == 0 {
+		return &EmbeddingResponse{Model: params.Model, Object: "list"}, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		result   = &EmbeddingResponse{
+			Model:  params.Model,
+			Object: "list",
+			Data:   make([]EmbeddingData, len(params.Inputs)),
+		}
+	)
+
+	sem := make(chan struct{}, params.Concurrency)
+	var wg sync.WaitGroup
+	for _, batch := range splitBatches(&params) {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(batch embedBatch) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resp, err := s.embedBatch(ctx, params, batch)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("embed inputs %d-%d: %w", batch.start, batch.start+len(batch.inputs)-1, err)
+					cancel()
+				}
+				return
+			}
+			for _, d := range resp.Data {
+				d.Index += batch.start
+				result.Data[d.Index] = d
+			}
+			result.Usage.TotalTokens += resp.Usage.TotalTokens
+			if resp.Model != "" {
+				result.Model = resp.Model
+			}
+		}(batch)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *ChatService) embedBatch(ctx context.Context, params EmbedManyParams, batch embedBatch) (*EmbeddingResponse, error) {
+	resp, err := s.Embeddings(ctx, EmbeddingParams{
+		Input:          EmbeddingTexts(batch.inputs),
+		Model:          params.Model,
+		Dimensions:     params.Dimensions,
+		EncodingFormat: params.EncodingFormat,
+		User:           params.User,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(batch.inputs) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(batch.inputs), len(resp.Data))
+	}
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(batch.inputs) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,166 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// embeddingServer answers each input with a one-dimensional embedding equal
+// to the number at the end of the input, e.g. "doc-7" embeds to [7].
+func embeddingServer(t *testing.T, before func(inputs []string) bool) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if before != nil && !before(req.Input) {
+			w.WriteHeader(503)
+			json.NewEncoder(w).Encode(map[string]string{"error": "busy", "code": "PROVIDER_ERROR"})
+			return
+		}
+
+		resp := EmbeddingResponse{Usage: EmbeddingUsage{TotalTokens: len(req.Input)}}
+		// Answer in reverse to make sure Index, not position, is honoured.
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			n, _ := strconv.Atoi(req.Input[i][strings.LastIndex(req.Input[i], "-")+1:])
+			resp.Data = append(resp.Data, EmbeddingData{Embedding: []float32{float32(n)}, Index: i})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	return server, &calls
+}
+
+func TestEmbedMany_OrderAndUsage(t *testing.T) {
+	server, calls := embeddingServer(t, nil)
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	inputs := make([]string, 25)
+	for i := range inputs {
+		inputs[i] = "doc-" + strconv.Itoa(i)
+	}
+
+	resp, err := client.Chat.EmbedMany(context.Background(), EmbedManyParams{
+		Model:       "text-embedding-3-small",
+		Inputs:      inputs,
+		BatchSize:   4,
+		Concurrency: 3,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if calls.Load() != 7 {
+		t.Errorf("expected 7 batches, got %d", calls.Load())
+	}
+	if resp.Usage.TotalTokens != 25 {
+		t.Errorf("expected summed usage 25, got %d", resp.Usage.TotalTokens)
+	}
+	for i, d := range resp.Data {
+		if d.Index != i || d.Embedding[0] != float32(i) {
+			t.Fatalf("data[%d] = %+v, want index and embedding %d", i, d, i)
+		}
+	}
+}
+
+func TestEmbedMany_RetriesTransientErrors(t *testing.T) {
+	var failed atomic.Bool
+	server, calls := embeddingServer(t, func([]string) bool {
+		return !failed.CompareAndSwap(false, true)
+	})
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond}))
+
+	resp, err := client.Chat.EmbedMany(context.Background(), EmbedManyParams{
+		Inputs: []string{"doc-0", "doc-1"},
+	})
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if len(resp.Data) != 2 || calls.Load() != 2 {
+		t.Errorf("expected 2 embeddings after 2 calls, got %d after %d", len(resp.Data), calls.Load())
+	}
+}
+
+func TestEmbedMany_FailsAfterRetries(t *testing.T) {
+	server, calls := embeddingServer(t, func([]string) bool { return false })
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}))
+
+	_, err := client.Chat.EmbedMany(context.Background(), EmbedManyParams{
+		Inputs: []string{"doc-0"},
+	}, WithRequestRetryPolicy(RetryPolicy{}))
+	if !errors.Is(err, ErrProvider) {
+		t.Fatalf("expected ErrProvider, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected the per-call policy to disable retries, got %d calls", calls.Load())
+	}
+}
+
+func TestEmbedMany_ForwardsParams(t *testing.T) {
+	var got EmbeddingParams
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(EmbeddingResponse{Data: []EmbeddingData{{Embedding: []float32{1}}}})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	dims, user := 256, "user-1"
+	_, err := client.Chat.EmbedMany(context.Background(), EmbedManyParams{
+		Model:          "text-embedding-3-small",
+		Inputs:         []string{"doc-0"},
+		Dimensions:     &dims,
+		EncodingFormat: EncodingFloat,
+		User:           &user,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.Dimensions == nil || *got.Dimensions != 256 || got.EncodingFormat != EncodingFloat || got.User == nil || *got.User != "user-1" {
+		t.Errorf("expected the batch to carry the caller's params, got %+v", got)
+	}
+}
+
+func TestSplitBatches_TokenLimit(t *testing.T) {
+	params := EmbedManyParams{
+		Inputs:         []string{"aaaa", "bbbbbbbb", "cc", "dddddddddddddddd", "e"},
+		MaxBatchTokens: 3,
+		TokenCounter:   func(s string) int { return len(s) / 4 },
+	}
+	params.setDefaults()
+
+	var got [][]string
+	for _, b := range splitBatches(&params) {
+		got = append(got, b.inputs)
+	}
+
+	want := [][]string{{"aaaa", "bbbbbbbb", "cc"}, {"dddddddddddddddd"}, {"e"}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if strings.Join(got[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("batch %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
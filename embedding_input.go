@@ -0,0 +1,157 @@
+package cencori
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var errEmptyEmbeddingInput = errors.New("cencori: embedding input is empty")
+
+type embeddingInputKind int
+
+const (
+	inputNone embeddingInputKind = iota
+	inputText
+	inputTexts
+	inputTokens
+	inputTokenBatches
+)
+
+// EmbeddingInput is the input of an embedding request: a single string, a
+// slice of strings, a token array or a slice of token arrays. Create one with
+// EmbeddingText, EmbeddingTexts, EmbeddingTokens or EmbeddingTokenBatches.
+type EmbeddingInput struct {
+	kind   embeddingInputKind
+	texts  []string
+	tokens [][]int
+}
+
+// EmbeddingText embeds a single string.
+func EmbeddingText(text string) EmbeddingInput {
+	return EmbeddingInput{kind: inputText, texts: []string{text}}
+}
+
+// EmbeddingTexts embeds each string separately.
+func EmbeddingTexts(texts []string) EmbeddingInput {
+	return EmbeddingInput{kind: inputTexts, texts: texts}
+}
+
+// EmbeddingTokens embeds a single pre-tokenized input.
+func EmbeddingTokens(tokens []int) EmbeddingInput {
+	return EmbeddingInput{kind: inputTokens, tokens: [][]int{tokens}}
+}
+
+// EmbeddingTokenBatches embeds each pre-tokenized input separately.
+func EmbeddingTokenBatches(batches [][]int) EmbeddingInput {
+	return EmbeddingInput{kind: inputTokenBatches, tokens: batches}
+}
+
+// Len returns the number of embeddings the input will produce.
+func (in EmbeddingInput) Len() int {
+	switch in.kind {
+	case inputText, inputTexts:
+		return len(in.texts)
+	case inputTokens, inputTokenBatches:
+		return len(in.tokens)
+	case inputNone:
+	}
+	return 0
+}
+
+// Texts returns the string inputs, or nil for token inputs.
+func (in EmbeddingInput) Texts() []string {
+	return in.texts
+}
+
+// Tokens returns the token inputs, or nil for string inputs.
+func (in EmbeddingInput) Tokens() [][]int {
+	return in.tokens
+}
+
+// MarshalJSON implements json.Marshaler.
+func (in EmbeddingInput) MarshalJSON() ([]byte, error) {
+	switch in.kind {
+	case inputText:
+		return json.Marshal(in.texts[0])
+	case inputTexts:
+		return json.Marshal(in.texts)
+	case inputTokens:
+		return json.Marshal(in.tokens[0])
+	case inputTokenBatches:
+		return json.Marshal(in.tokens)
+	case inputNone:
+	}
+	return []byte("null"), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (in *EmbeddingInput) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*in = EmbeddingInput{}
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*in = EmbeddingText(text)
+		return nil
+	}
+	var texts []string
+	if err := json.Unmarshal(data, &texts); err == nil {
+		*in = EmbeddingTexts(texts)
+		return nil
+	}
+	var tokens []int
+	if err := json.Unmarshal(data, &tokens); err == nil {
+		*in = EmbeddingTokens(tokens)
+		return nil
+	}
+	var batches [][]int
+	if err := json.Unmarshal(data, &batches); err == nil {
+		*in = EmbeddingTokenBatches(batches)
+		return nil
+	}
+	return fmt.Errorf("embedding input: unsupported value %s", data)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts embeddings encoded as
+// a JSON array of numbers or, for EncodingBase64, as a base64 string of
+// little-endian float32 values.
+func (d *EmbeddingData) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Embedding json.RawMessage `json:"embedding"`
+		Index     int             `json:"index"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	d.Index = raw.Index
+	d.Embedding = nil
+
+	if len(raw.Embedding) == 0 || raw.Embedding[0] != '"' {
+		return json.Unmarshal(raw.Embedding, &d.Embedding)
+	}
+
+	var encoded string
+	if err := json.Unmarshal(raw.Embedding, &encoded); err != nil {
+		return err
+	}
+	buf, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("decode base64 embedding: %w", err)
+	}
+	if len(buf)%4 != 0 {
+		return fmt.Errorf("decode base64 embedding: %d bytes is not a multiple of 4", len(buf))
+	}
+	d.Embedding = make([]float32, len(buf)/4)
+	for i := range d.Embedding {
+		d.Embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cencori
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEmbeddingInput_JSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input EmbeddingInput
+		want  string
+		len   int
+	}{
+		{"text", EmbeddingText("hi"), `"hi"`, 1},
+		{"texts", EmbeddingTexts([]string{"a", "b"}), `["a","b"]`, 2},
+		{"tokens", EmbeddingTokens([]int{1, 2, 3}), `[1,2,3]`, 1},
+		{"token batches", EmbeddingTokenBatches([][]int{{1}, {2, 3}}), `[[1],[2,3]]`, 2},
+		{"empty", EmbeddingInput{}, `null`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.input)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal = %s, want %s", data, tt.want)
+			}
+			if tt.input.Len() != tt.len {
+				t.Errorf("Len = %d, want %d", tt.input.Len(), tt.len)
+			}
+
+			var decoded EmbeddingInput
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if again, _ := json.Marshal(decoded); string(again) != tt.want {
+				t.Errorf("round trip = %s, want %s", again, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddingData_Base64(t *testing.T) {
+	want := []float32{0.5, -1.25, 3}
+	buf := make([]byte, 4*len(want))
+	for i, f := range want {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(f))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["encoding_format"] != "base64" {
+			t.Errorf("expected encoding_format base64, got %v", req["encoding_format"])
+		}
+		if req["dimensions"] != float64(3) {
+			t.Errorf("expected dimensions 3, got %v", req["dimensions"])
+		}
+		if req["user"] != "user-1" {
+			t.Errorf("expected user user-1, got %v", req["user"])
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{
+				{"embedding": base64.StdEncoding.EncodeToString(buf), "index": 0},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	dims, user := 3, "user-1"
+	resp, err := client.Chat.Embeddings(context.Background(), EmbeddingParams{
+		Input:          EmbeddingText("hi"),
+		Model:          "text-embedding-3-small",
+		Dimensions:     &dims,
+		EncodingFormat: EncodingBase64,
+		User:           &user,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := resp.Data[0].Embedding
+	if len(got) != len(want) {
+		t.Fatalf("expected %d values, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("embedding[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEmbeddings_EmptyInput(t *testing.T) {
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL("http://127.0.0.1:0"))
+
+	_, err := client.Chat.Embeddings(context.Background(), EmbeddingParams{Model: "text-embedding-3-small"})
+	if !errors.Is(err, errEmptyEmbeddingInput) {
+		t.Fatalf("expected empty input error, got %v", err)
+	}
+}
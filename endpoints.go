@@ -0,0 +1,218 @@
+package cencori
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint is a base URL the client can send requests to.
+type Endpoint struct {
+	URL string
+	// Priority orders endpoints; lower values are preferred. Healthy endpoints
+	// with the same priority are ordered by observed latency.
+	Priority int
+}
+
+// FailoverConfig configures multi-endpoint failover.
+type FailoverConfig struct {
+	Endpoints []Endpoint
+	// Cooldown is how long an endpoint is skipped after a connection error (default 30s).
+	Cooldown time.Duration
+	// HealthCheckInterval enables active health checks when greater than zero.
+	// Call Client.Close to stop them.
+	HealthCheckInterval time.Duration
+	// HealthCheckPath is requested with GET during health checks (default "/").
+	HealthCheckPath string
+}
+
+// EndpointStatus is a point-in-time view of one endpoint.
+type EndpointStatus struct {
+	URL       string        `json:"url"`
+	Priority  int           `json:"priority"`
+	Healthy   bool          `json:"healthy"`
+	Latency   time.Duration `json:"latency"`
+	LastError string        `json:"last_error,omitempty"`
+}
+
+type endpointState struct {
+	Endpoint
+	downUntil time.Time
+	latency   time.Duration
+	lastErr   error
+}
+
+// endpointPool tracks the health and latency of every configured endpoint.
+type endpointPool struct {
+	cfg       FailoverConfig
+	now       func() time.Time
+	mu        sync.Mutex
+	endpoints []*endpointState
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+func newEndpointPool(cfg FailoverConfig) *endpointPool {
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.HealthCheckPath == "" {
+		cfg.HealthCheckPath = "/"
+	}
+
+	p := &endpointPool{cfg: cfg, now: time.Now, stop: make(chan struct{})}
+	for _, ep := range cfg.Endpoints {
+		ep.URL = strings.TrimRight(ep.URL, "/")
+		p.endpoints = append(p.endpoints, &endpointState{Endpoint: ep})
+	}
+	return p
+}
+
+// order returns the endpoint URLs in the order they should be tried:
+// healthy endpoints by priority then latency, followed by endpoints that are
+// cooling down so that a request is still attempted when everything looks down.
+func (p *endpointPool) order() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	eps := make([]*endpointState, len(p.endpoints))
+	copy(eps, p.endpoints)
+	sort.SliceStable(eps, func(i, j int) bool {
+		a, b := eps[i], eps[j]
+		aUp, bUp := !now.Before(a.downUntil), !now.Before(b.downUntil)
+		if aUp != bUp {
+			return aUp
+		}
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.latency < b.latency
+	})
+
+	urls := make([]string, len(eps))
+	for i, ep := range eps {
+		urls[i] = ep.URL
+	}
+	return urls
+}
+
+func (p *endpointPool) find(url string) *endpointState {
+	for _, ep := range p.endpoints {
+		if ep.URL == url {
+			return ep
+		}
+	}
+	return nil
+}
+
+func (p *endpointPool) markDown(url string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ep := p.find(url); ep != nil {
+		ep.downUntil = p.now().Add(p.cfg.Cooldown)
+		ep.lastErr = err
+	}
+}
+
+// observe records a successful round trip and its latency as an
+// exponentially weighted moving average.
+func (p *endpointPool) observe(url string, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ep := p.find(url)
+	if ep == nil {
+		return
+	}
+	ep.downUntil = time.Time{}
+	ep.lastErr = nil
+	if ep.latency == 0 {
+		ep.latency = latency
+		return
+	}
+	ep.latency = (ep.latency*4 + latency) / 5
+}
+
+func (p *endpointPool) status() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	out := make([]EndpointStatus, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		out[i] = EndpointStatus{
+			URL:      ep.URL,
+			Priority: ep.Priority,
+			Healthy:  !now.Before(ep.downUntil),
+			Latency:  ep.latency,
+		}
+		if ep.lastErr != nil {
+			out[i].LastError = ep.lastErr.Error()
+		}
+	}
+	return out
+}
+
+// healthCheck probes every endpoint on an interval until close is called.
+func (p *endpointPool) healthCheck(httpClient *http.Client) {
+	ticker := time.NewTicker(p.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			for _, url := range p.order() {
+				p.probe(httpClient, url)
+			}
+		}
+	}
+}
+
+func (p *endpointPool) probe(httpClient *http.Client, url string) {
+	ctx, cancel := context.WithTimeout(context.Background(), min(p.cfg.HealthCheckInterval, 5*time.Second))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+p.cfg.HealthCheckPath, http.NoBody)
+	if err != nil {
+		p.markDown(url, err)
+		return
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		p.markDown(url, err)
+		return
+	}
+	resp.Body.Close() //nolint:errcheck // Closing the response body; error can be ignored here.
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		p.markDown(url, &APIError{StatusCode: resp.StatusCode, Message: resp.Status})
+		return
+	}
+	p.observe(url, time.Since(start))
+}
+
+func (p *endpointPool) close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+// isConnectionError reports whether err happened before the request reached
+// the server, which makes it safe to replay against another endpoint.
+func isConnectionError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
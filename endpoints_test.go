@@ -0,0 +1,145 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// unreachableURL returns the URL of a server that has already been shut down.
+func unreachableURL() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	return url
+}
+
+func TestFailover_DoRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ChatResponse{ID: "from-secondary"})
+	}))
+	defer server.Close()
+
+	down := unreachableURL()
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURLs(down, server.URL))
+	defer client.Close()
+
+	resp, err := client.Chat.Create(context.Background(), &ChatParams{})
+	if err != nil {
+		t.Fatalf("expected failover to succeed, got %v", err)
+	}
+	if resp.ID != "from-secondary" {
+		t.Errorf("expected response from secondary, got %s", resp.ID)
+	}
+
+	status := client.Endpoints()
+	if status[0].URL != down || status[0].Healthy || status[0].LastError == "" {
+		t.Errorf("expected primary marked unhealthy, got %+v", status[0])
+	}
+	if !status[1].Healthy {
+		t.Errorf("expected secondary healthy, got %+v", status[1])
+	}
+
+	// The unhealthy primary is skipped while cooling down.
+	if order, _ := client.baseURLs(context.Background()); order[0] != server.URL {
+		t.Errorf("expected secondary first, got %v", order)
+	}
+}
+
+func TestFailover_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\": [{\"delta\": {\"content\": \"ok\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURLs(unreachableURL(), server.URL))
+	defer client.Close()
+
+	stream, err := client.Chat.Stream(context.Background(), &ChatParams{})
+	if err != nil {
+		t.Fatalf("expected failover to succeed, got %v", err)
+	}
+
+	var content string
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		content += chunk.Choices[0].Delta.Content
+	}
+	if content != "ok" {
+		t.Errorf("expected ok, got %s", content)
+	}
+}
+
+func TestFailover_AllEndpointsDown(t *testing.T) {
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURLs(unreachableURL(), unreachableURL()))
+	defer client.Close()
+
+	_, err := client.Chat.Create(context.Background(), &ChatParams{})
+	if err == nil {
+		t.Fatal("expected error when every endpoint is down")
+	}
+	if !isConnectionError(err) {
+		t.Errorf("expected connection error, got %v", err)
+	}
+}
+
+func TestEndpointPool_Order(t *testing.T) {
+	now := time.Now()
+	pool := newEndpointPool(FailoverConfig{
+		Endpoints: []Endpoint{
+			{URL: "https://slow.example.com/", Priority: 1},
+			{URL: "https://fast.example.com", Priority: 1},
+			{URL: "https://fallback.example.com", Priority: 2},
+		},
+		Cooldown: time.Minute,
+	})
+	pool.now = func() time.Time { return now }
+
+	pool.observe("https://slow.example.com", 300*time.Millisecond)
+	pool.observe("https://fast.example.com", 50*time.Millisecond)
+
+	want := []string{"https://fast.example.com", "https://slow.example.com", "https://fallback.example.com"}
+	if got := pool.order(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("order() = %v, want %v", got, want)
+	}
+
+	pool.markDown("https://fast.example.com", fmt.Errorf("refused"))
+	want = []string{"https://slow.example.com", "https://fallback.example.com", "https://fast.example.com"}
+	if got := pool.order(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("order() after markDown = %v, want %v", got, want)
+	}
+
+	now = now.Add(time.Minute)
+	if got := pool.order(); got[0] != "https://fast.example.com" {
+		t.Errorf("expected fast endpoint back after cooldown, got %v", got)
+	}
+}
+
+func TestEndpointPool_HealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			t.Errorf("expected /health, got %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	pool := newEndpointPool(FailoverConfig{
+		Endpoints:           []Endpoint{{URL: server.URL}},
+		HealthCheckInterval: time.Second,
+		HealthCheckPath:     "/health",
+	})
+	pool.markDown(server.URL, fmt.Errorf("refused"))
+	pool.probe(&http.Client{}, server.URL)
+
+	if status := pool.status(); !status[0].Healthy {
+		t.Errorf("expected endpoint healthy after probe, got %+v", status[0])
+	}
+}
@@ -31,6 +31,25 @@ func TestAPIError_Error(t *testing.T) {
 			},
 			want: "cencori: Server error (status: 500)",
 		},
+		{
+			name: "with request ID",
+			err: APIError{
+				StatusCode: 429,
+				Code:       "RATE_LIMIT_EXCEEDED",
+				Message:    "Too many requests",
+				RequestID:  "req-123",
+			},
+			want: "cencori: Too many requests (code: RATE_LIMIT_EXCEEDED, status: 429, request_id: req-123)",
+		},
+		{
+			name: "without code with request ID",
+			err: APIError{
+				StatusCode: 502,
+				Message:    "Bad gateway",
+				RequestID:  "req-456",
+			},
+			want: "cencori: Bad gateway (status: 502, request_id: req-456)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -67,6 +86,7 @@ func TestAPIError_fillSentinel(t *testing.T) {
 		{"INVALID_MODEL", ErrInvalidModel},
 		{"PROVIDER_ERROR", ErrProvider},
 		{"CONTENT_FILTERED", ErrContentFiltered},
+		{"SECURITY_VIOLATION", ErrSecurityViolation},
 	}
 
 	for _, tt := range tests {
@@ -81,6 +101,47 @@ func TestAPIError_fillSentinel(t *testing.T) {
 	}
 }
 
+func TestAPIError_Violation(t *testing.T) {
+	body := `{"code":"SECURITY_VIOLATION","error":"Blocked","details":{` +
+		`"violated_policy":"pii_protection","category":"pii","detected_entities":["EMAIL","PHONE"],"message_index":2}}`
+	err := handleError(&http.Response{StatusCode: 403, Body: io.NopCloser(bytes.NewBufferString(body))})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrSecurityViolation) {
+		t.Fatalf("expected a security violation, got %v", err)
+	}
+	v := apiErr.Violation
+	if v == nil {
+		t.Fatal("expected Violation to be parsed")
+	}
+	if v.Policy != "pii_protection" || v.Category != "pii" || len(v.EntityTypes) != 2 || v.EntityTypes[1] != "PHONE" {
+		t.Errorf("unexpected violation: %+v", v)
+	}
+	if v.MessageIndex == nil || *v.MessageIndex != 2 {
+		t.Errorf("expected message index 2, got %v", v.MessageIndex)
+	}
+
+	filtered := &APIError{Code: "CONTENT_FILTERED", Details: map[string]any{"category": "hate"}}
+	filtered.fillSentinel()
+	if filtered.Violation == nil || filtered.Violation.Category != "hate" || filtered.Violation.MessageIndex != nil {
+		t.Errorf("unexpected content filter details: %+v", filtered.Violation)
+	}
+
+	mixed := &APIError{Code: "SECURITY_VIOLATION", Details: map[string]any{
+		"policy": "pii_protection", "category": 7, "entity_types": []any{"EMAIL", 1}, "entities": []any{"EMAIL"}, "message_index": "2",
+	}}
+	mixed.fillSentinel()
+	if v := mixed.Violation; v == nil || v.Policy != "pii_protection" || v.Category != "" || len(v.EntityTypes) != 1 || v.MessageIndex != nil {
+		t.Errorf("expected fields of the wrong type to be dropped individually, got %+v", v)
+	}
+
+	empty := &APIError{Code: "CONTENT_FILTERED", Details: map[string]any{}}
+	empty.fillSentinel()
+	if !errors.Is(empty, ErrContentFiltered) || empty.Violation != nil {
+		t.Errorf("expected no Violation without details, got %+v", empty.Violation)
+	}
+}
+
 func TestHandleError(t *testing.T) {
 	tests := []struct {
 		name       string
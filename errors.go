@@ -1,6 +1,7 @@
 package cencori
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 )
@@ -10,14 +11,79 @@ type APIError struct {
 	Code       string         `json:"code"`
 	Message    string         `json:"error"`
 	Details    map[string]any `json:"details,omitempty"`
-	Err        error          `json:"-"`
+	// RequestID is the gateway request ID, from the error body or the
+	// response headers; empty if the gateway did not report one.
+	RequestID string `json:"request_id,omitempty"`
+	// Violation is parsed from Details for SECURITY_VIOLATION and
+	// CONTENT_FILTERED errors; nil otherwise.
+	Violation *ViolationDetails `json:"-"`
+	Err       error             `json:"-"`
+}
+
+// ViolationDetails describes why the gateway blocked a request or response.
+type ViolationDetails struct {
+	// Policy is the security policy that was violated, e.g. "pii_protection".
+	Policy string `json:"policy,omitempty"`
+	// Category is the kind of violation, e.g. "pii", "prompt_injection" or "hate".
+	Category string `json:"category,omitempty"`
+	// EntityTypes are the detected sensitive entity types, e.g. "EMAIL".
+	EntityTypes []string `json:"entity_types,omitempty"`
+	// MessageIndex is the index of the offending message in the request, if known.
+	MessageIndex *int `json:"message_index,omitempty"`
+}
+
+// parseViolation reads the violation from the error details, also
+// accepting the alternative field names used by some gateway versions.
+// Fields are decoded one at a time, so a field of an unexpected type is
+// dropped without losing the others.
+func parseViolation(details map[string]any) *ViolationDetails {
+	if len(details) == 0 {
+		return nil
+	}
+	var v ViolationDetails
+	for _, name := range []string{"policy", "violated_policy"} {
+		if v.Policy = detailField[string](details, name); v.Policy != "" {
+			break
+		}
+	}
+	v.Category = detailField[string](details, "category")
+	for _, name := range []string{"entity_types", "detected_entities", "entities"} {
+		if v.EntityTypes = detailField[[]string](details, name); v.EntityTypes != nil {
+			break
+		}
+	}
+	v.MessageIndex = detailField[*int](details, "message_index")
+	return &v
+}
+
+// detailField decodes details[name] as a T, returning the zero value if it
+// is missing or of another type.
+func detailField[T any](details map[string]any, name string) T {
+	var v T
+	raw, ok := details[name]
+	if !ok {
+		return v
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return v
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		var zero T
+		return zero
+	}
+	return v
 }
 
 func (e *APIError) Error() string {
+	var requestID string
+	if e.RequestID != "" {
+		requestID = ", request_id: " + e.RequestID
+	}
 	if e.Code != "" {
-		return fmt.Sprintf("cencori: %s (code: %s, status: %d)", e.Message, e.Code, e.StatusCode)
+		return fmt.Sprintf("cencori: %s (code: %s, status: %d%s)", e.Message, e.Code, e.StatusCode, requestID)
 	}
-	return fmt.Sprintf("cencori: %s (status: %d)", e.Message, e.StatusCode)
+	return fmt.Sprintf("cencori: %s (status: %d%s)", e.Message, e.StatusCode, requestID)
 }
 
 func (e *APIError) Unwrap() error {
@@ -38,8 +104,12 @@ func (e *APIError) fillSentinel() {
 		e.Err = ErrInvalidModel
 	case "PROVIDER_ERROR":
 		e.Err = ErrProvider
+	case "SECURITY_VIOLATION":
+		e.Err = ErrSecurityViolation
+		e.Violation = parseViolation(e.Details)
 	case "CONTENT_FILTERED":
 		e.Err = ErrContentFiltered
+		e.Violation = parseViolation(e.Details)
 	}
 }
 
@@ -52,4 +122,5 @@ var (
 	ErrInvalidModel        = errors.New("INVALID_MODEL")
 	ErrProvider            = errors.New("PROVIDER_ERROR")
 	ErrContentFiltered     = errors.New("CONTENT_FILTERED")
+	ErrCircuitOpen         = errors.New("CIRCUIT_OPEN")
 )
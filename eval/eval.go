@@ -0,0 +1,177 @@
+// Package eval runs a dataset of prompts through one or more models with
+// cencori's ChatService, scores the outputs with pluggable graders and
+// summarizes quality, latency and cost per model so that model or prompt
+// changes can be compared before they ship.
+package eval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cencori/cencori-go"
+)
+
+// Case is one dataset entry.
+type Case struct {
+	ID       string            `json:"id"`
+	Messages []cencori.Message `json:"messages"`
+	// Expected is the reference output graders compare against, if any.
+	Expected string            `json:"expected,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// LoadJSONL reads a dataset with one Case per line. Cases without an ID are
+// numbered by line ("case-1", ...).
+func LoadJSONL(r io.Reader) ([]Case, error) {
+	var cases []Case
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var c Case
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("dataset line %d: %w", line, err)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("case-%d", line)
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read dataset: %w", err)
+	}
+	return cases, nil
+}
+
+// Price is the cost of a model in USD per million tokens.
+type Price = cencori.Price
+
+// Config configures Run.
+type Config struct {
+	Chat    *cencori.ChatService
+	Models  []string
+	Graders []Grader
+	// Concurrency is the number of requests in flight per model and the
+	// number of outputs graded at once (default 4).
+	Concurrency int
+	// Limiter, if set, paces the chat requests.
+	Limiter cencori.Limiter
+	// Temperature and MaxTokens are passed through to every request when set.
+	Temperature *float64
+	MaxTokens   *int
+	// Pricing maps model names to prices used to estimate cost. Models
+	// without a price report zero cost.
+	Pricing map[string]Price
+}
+
+// Result is the outcome of one case on one model.
+type Result struct {
+	CaseID  string
+	Model   string
+	Output  string
+	Err     error
+	Latency time.Duration
+	Usage   cencori.Usage
+	Cost    float64
+	// Scores maps grader names to their score. It is empty when Err is set.
+	Scores map[string]Score
+}
+
+// Run sends every case to every model, grades the outputs and returns the
+// report. Failed requests are recorded in the results rather than aborting
+// the run; the returned error is only set for invalid configuration or when
+// ctx is done.
+func Run(ctx context.Context, cfg Config, cases []Case) (*Report, error) {
+	if cfg.Chat == nil {
+		return nil, errors.New("eval: Config.Chat is required")
+	}
+	if len(cfg.Models) == 0 {
+		return nil, errors.New("eval: no models to evaluate")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+
+	var results []Result
+	for _, model := range cfg.Models {
+		modelResults, err := runModel(ctx, cfg, model, cases)
+		if err != nil {
+			return nil, fmt.Errorf("eval %s: %w", model, err)
+		}
+		results = append(results, modelResults...)
+	}
+	return newReport(cfg, results), nil
+}
+
+func runModel(ctx context.Context, cfg Config, model string, cases []Case) ([]Result, error) {
+	params := cencori.CreateManyParams{
+		Requests:    make([]cencori.ChatParams, len(cases)),
+		Concurrency: cfg.Concurrency,
+		Limiter:     cfg.Limiter,
+	}
+	for i, c := range cases {
+		params.Requests[i] = cencori.ChatParams{
+			Model:       model,
+			Messages:    c.Messages,
+			Temperature: cfg.Temperature,
+			MaxTokens:   cfg.MaxTokens,
+		}
+	}
+
+	resp, err := cfg.Chat.CreateMany(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(cases))
+	sem := make(chan struct{}, cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i, r := range resp.Results {
+		results[i] = Result{CaseID: cases[i].ID, Model: model, Err: r.Err, Latency: r.Latency}
+		if r.Err != nil {
+			continue
+		}
+		results[i].Usage = r.Response.Usage
+		results[i].Cost = cfg.Pricing[model].Cost(r.Response.Usage)
+		if len(r.Response.Choices) > 0 {
+			results[i].Output = r.Response.Choices[0].Message.Content
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Scores = grade(ctx, cfg.Graders, cases[i], results[i].Output)
+		}(i)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func grade(ctx context.Context, graders []Grader, c Case, output string) map[string]Score {
+	scores := make(map[string]Score, len(graders))
+	for _, g := range graders {
+		score, err := g.Grade(ctx, c, output)
+		if err != nil {
+			score = Score{Err: err}
+		}
+		scores[g.Name()] = score
+	}
+	return scores
+}
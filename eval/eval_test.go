@@ -0,0 +1,161 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cencori/cencori-go"
+)
+
+// newServer answers "good" with the expected answer, "bad" with a wrong one,
+// fails "broken", and lets "judge" score answers containing "Paris" 9 and
+// everything else 2.
+func newServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/embeddings" {
+			var req struct {
+				Input []string `json:"input"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			resp := cencori.EmbeddingResponse{}
+			for i, in := range req.Input {
+				v := []float32{1, 0}
+				if !strings.Contains(strings.ToLower(in), "paris") {
+					v = []float32{0, 1}
+				}
+				resp.Data = append(resp.Data, cencori.EmbeddingData{Embedding: v, Index: i})
+			}
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+
+		var params cencori.ChatParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		last := params.Messages[len(params.Messages)-1].Content
+
+		var content string
+		switch params.Model {
+		case "good":
+			content = "Paris"
+		case "bad":
+			content = "Lyon"
+		case "judge":
+			score := 2
+			if strings.Contains(last, "Answer to grade:\nParis") {
+				score = 9
+			}
+			content = `Sure: {"score": ` + string(rune('0'+score)) + `, "reason": "ok"}`
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "bad model", "code": "INVALID_MODEL"})
+			return
+		}
+
+		resp := cencori.ChatResponse{Usage: cencori.Usage{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110}}
+		resp.Choices = append(resp.Choices, struct {
+			Index        int             `json:"index"`
+			Message      cencori.Message `json:"message"`
+			FinishReason string          `json:"finish_reason"`
+		}{Message: cencori.Message{Role: "assistant", Content: content}})
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestRun(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+	client, _ := cencori.NewClient(cencori.WithAPIKey("test-key"), cencori.WithBaseURL(server.URL))
+
+	cases, err := LoadJSONL(strings.NewReader(
+		`{"messages":[{"role":"user","content":"Capital of France?"}],"expected":"Paris"}` + "\n" +
+			`{"id":"again","messages":[{"role":"user","content":"France's capital?"}],"expected":"paris"}` + "\n"))
+	if err != nil {
+		t.Fatalf("LoadJSONL failed: %v", err)
+	}
+
+	report, err := Run(context.Background(), Config{
+		Chat:   client.Chat,
+		Models: []string{"good", "bad", "broken"},
+		Graders: []Grader{
+			ExactMatch{IgnoreCase: true},
+			EmbeddingSimilarity{Embedder: client.Chat},
+			Judge{Generator: client.Chat, Model: "judge"},
+		},
+		Pricing: map[string]Price{"good": {PromptPerMillion: 5, CompletionPerMillion: 15}},
+	}, cases)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(report.Results) != 6 || report.Results[0].CaseID != "case-1" || report.Results[1].CaseID != "again" {
+		t.Fatalf("unexpected results: %+v", report.Results)
+	}
+
+	good, bad, broken := report.Models[0], report.Models[1], report.Models[2]
+	for _, g := range []string{"exact", "similarity", "judge"} {
+		if good.Graders[g].PassRate != 1 {
+			t.Errorf("good: expected %s pass rate 1, got %+v", g, good.Graders[g])
+		}
+		if bad.Graders[g].PassRate != 0 {
+			t.Errorf("bad: expected %s pass rate 0, got %+v", g, bad.Graders[g])
+		}
+	}
+	if broken.Errors != 2 || broken.Graders["exact"].Graded != 0 {
+		t.Errorf("broken: expected 2 errors and nothing graded, got %+v", broken)
+	}
+	if good.Usage.TotalTokens != 220 || bad.Cost != 0 {
+		t.Errorf("unexpected usage or cost: %+v, %+v", good, bad)
+	}
+	if want := 2 * (100*5 + 10*15) / 1e6; good.Cost != want {
+		t.Errorf("expected cost %v, got %v", want, good.Cost)
+	}
+
+	var table strings.Builder
+	if err := report.WriteTable(&table); err != nil {
+		t.Fatalf("WriteTable failed: %v", err)
+	}
+	if !strings.Contains(table.String(), "JUDGE") || !strings.Contains(table.String(), "100.0%") {
+		t.Errorf("unexpected table:\n%s", table.String())
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	g := JSONSchema{Schema: json.RawMessage(`{
+		"type": "object",
+		"required": ["name", "tags"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"age": {"type": "integer", "minimum": 0},
+			"tags": {"type": "array", "items": {"enum": ["a", "b"]}, "maxItems": 2}
+		}
+	}`)}
+
+	for _, tt := range []struct {
+		output string
+		pass   bool
+	}{
+		{`{"name": "x", "tags": ["a"]}`, true},
+		{"```json\n{\"name\": \"x\", \"tags\": [], \"age\": 3}\n```", true},
+		{`{"name": "x"}`, false},
+		{`{"name": "", "tags": []}`, false},
+		{`{"name": "x", "tags": ["c"]}`, false},
+		{`{"name": "x", "tags": [], "age": 1.5}`, false},
+		{`{"name": "x", "tags": [], "extra": 1}`, false},
+		{`not json`, false},
+	} {
+		score, err := g.Grade(context.Background(), Case{}, tt.output)
+		if err != nil {
+			t.Fatalf("Grade(%q) failed: %v", tt.output, err)
+		}
+		if score.Pass != tt.pass {
+			t.Errorf("Grade(%q) pass = %v, want %v (%s)", tt.output, score.Pass, tt.pass, score.Reason)
+		}
+	}
+}
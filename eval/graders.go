@@ -0,0 +1,269 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/cencori/cencori-go"
+)
+
+// Score is a grader's verdict on one output.
+type Score struct {
+	// Value is the score between 0 and 1.
+	Value float64
+	// Pass reports whether the output meets the grader's bar.
+	Pass bool
+	// Reason explains the score when the grader can.
+	Reason string
+	// Err is set when the grader itself failed; the output is then unscored.
+	Err error
+}
+
+func passFail(pass bool, reason string) Score {
+	if pass {
+		return Score{Value: 1, Pass: true, Reason: reason}
+	}
+	return Score{Reason: reason}
+}
+
+// Grader scores a model output for a case.
+type Grader interface {
+	Name() string
+	Grade(ctx context.Context, c Case, output string) (Score, error)
+}
+
+// ExactMatch passes outputs equal to Case.Expected.
+type ExactMatch struct {
+	IgnoreCase bool
+	// KeepSpace disables trimming leading and trailing whitespace before comparing.
+	KeepSpace bool
+}
+
+func (g ExactMatch) Name() string { return "exact" }
+
+func (g ExactMatch) Grade(_ context.Context, c Case, output string) (Score, error) {
+	want := c.Expected
+	if !g.KeepSpace {
+		want, output = strings.TrimSpace(want), strings.TrimSpace(output)
+	}
+	if g.IgnoreCase {
+		return passFail(strings.EqualFold(want, output), ""), nil
+	}
+	return passFail(want == output, ""), nil
+}
+
+// Regex passes outputs matching Pattern, or Case.Expected compiled as a
+// regular expression when Pattern is nil.
+type Regex struct {
+	Pattern *regexp.Regexp
+}
+
+func (g Regex) Name() string { return "regex" }
+
+func (g Regex) Grade(_ context.Context, c Case, output string) (Score, error) {
+	re := g.Pattern
+	if re == nil {
+		var err error
+		if re, err = regexp.Compile(c.Expected); err != nil {
+			return Score{}, fmt.Errorf("compile expected pattern: %w", err)
+		}
+	}
+	return passFail(re.MatchString(output), ""), nil
+}
+
+// JSONSchema passes outputs that are valid JSON and, when Schema is set,
+// conform to it. A surrounding Markdown code fence is ignored. See
+// ValidateSchema for the supported keywords.
+type JSONSchema struct {
+	Schema json.RawMessage
+}
+
+func (g JSONSchema) Name() string { return "json_schema" }
+
+func (g JSONSchema) Grade(_ context.Context, _ Case, output string) (Score, error) {
+	var value any
+	if err := json.Unmarshal([]byte(stripFence(output)), &value); err != nil {
+		return passFail(false, "invalid JSON: "+err.Error()), nil
+	}
+	if len(g.Schema) == 0 {
+		return passFail(true, ""), nil
+	}
+
+	var schema any
+	if err := json.Unmarshal(g.Schema, &schema); err != nil {
+		return Score{}, fmt.Errorf("decode schema: %w", err)
+	}
+	if err := ValidateSchema(schema, value); err != nil {
+		return passFail(false, err.Error()), nil
+	}
+	return passFail(true, ""), nil
+}
+
+// stripFence removes a Markdown code fence around s.
+func stripFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") || len(s) < 6 {
+		return s
+	}
+	s = strings.TrimSuffix(s[3:], "```")
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[i+1:] // drop the language tag line
+	}
+	return strings.TrimSpace(s)
+}
+
+// EmbeddingSimilarity scores outputs by the cosine similarity of their
+// embedding to the embedding of Case.Expected.
+type EmbeddingSimilarity struct {
+	Embedder cencori.Embedder
+	// Model is the embedding model (default "text-embedding-3-small").
+	Model string
+	// Threshold is the similarity needed to pass (default 0.85).
+	Threshold float64
+}
+
+func (g EmbeddingSimilarity) Name() string { return "similarity" }
+
+func (g EmbeddingSimilarity) Grade(ctx context.Context, c Case, output string) (Score, error) {
+	model, threshold := g.Model, g.Threshold
+	if model == "" {
+		model = "text-embedding-3-small"
+	}
+	if threshold <= 0 {
+		threshold = 0.85
+	}
+
+	resp, err := g.Embedder.Embeddings(ctx, cencori.EmbeddingParams{
+		Input: cencori.EmbeddingTexts([]string{c.Expected, output}),
+		Model: model,
+	})
+	if err != nil {
+		return Score{}, err
+	}
+	vectors := make([][]float32, 2)
+	for _, d := range resp.Data {
+		if d.Index >= 0 && d.Index < 2 {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	if vectors[0] == nil || vectors[1] == nil {
+		return Score{}, errors.New("expected 2 embeddings")
+	}
+
+	sim := cosine(vectors[0], vectors[1])
+	return Score{Value: math.Max(sim, 0), Pass: sim >= threshold, Reason: fmt.Sprintf("similarity %.3f", sim)}, nil
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// Generator produces chat completions. *cencori.ChatService satisfies it.
+type Generator interface {
+	Create(ctx context.Context, params *cencori.ChatParams, opts ...cencori.RequestOption) (*cencori.ChatResponse, error)
+}
+
+var _ Generator = (*cencori.ChatService)(nil)
+
+// DefaultJudgeRubric is the rubric used by Judge when none is set.
+const DefaultJudgeRubric = "Score how well the answer responds to the conversation. " +
+	"If a reference answer is given, score how closely the answer agrees with it."
+
+// Judge asks a model to score outputs from 1 to 10 against a rubric.
+type Judge struct {
+	Generator Generator
+	// Model is the judging model.
+	Model string
+	// Rubric tells the judge what to look for (default DefaultJudgeRubric).
+	Rubric string
+	// PassScore is the minimum score out of 10 needed to pass (default 7).
+	PassScore int
+}
+
+func (g Judge) Name() string { return "judge" }
+
+func (g Judge) Grade(ctx context.Context, c Case, output string) (Score, error) {
+	rubric, passScore := g.Rubric, g.PassScore
+	if rubric == "" {
+		rubric = DefaultJudgeRubric
+	}
+	if passScore <= 0 {
+		passScore = 7
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Conversation:\n")
+	for _, m := range c.Messages {
+		fmt.Fprintf(&prompt, "%s: %s\n", m.Role, m.Content)
+	}
+	if c.Expected != "" {
+		fmt.Fprintf(&prompt, "\nReference answer:\n%s\n", c.Expected)
+	}
+	fmt.Fprintf(&prompt, "\nAnswer to grade:\n%s", output)
+
+	temp := 0.0
+	resp, err := g.Generator.Create(ctx, &cencori.ChatParams{
+		Model:       g.Model,
+		Temperature: &temp,
+		Messages: []cencori.Message{
+			{Role: "system", Content: "You are an impartial grader. " + rubric +
+				` Reply with JSON only: {"score": <integer 1-10>, "reason": "<one sentence>"}`},
+			{Role: "user", Content: prompt.String()},
+		},
+	})
+	if err != nil {
+		return Score{}, fmt.Errorf("judge: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return Score{}, errors.New("judge: empty response")
+	}
+
+	verdict, err := parseVerdict(resp.Choices[0].Message.Content)
+	if err != nil {
+		return Score{}, fmt.Errorf("judge: %w", err)
+	}
+	return Score{
+		Value:  float64(verdict.Score) / 10,
+		Pass:   verdict.Score >= passScore,
+		Reason: verdict.Reason,
+	}, nil
+}
+
+type verdict struct {
+	Score  int    `json:"score"`
+	Reason string `json:"reason"`
+}
+
+// parseVerdict extracts the judge's JSON object from its reply.
+func parseVerdict(text string) (verdict, error) {
+	var v verdict
+	start, end := strings.IndexByte(text, '{'), strings.LastIndexByte(text, '}')
+	if start < 0 || end < start {
+		return v, fmt.Errorf("no verdict in %q", text)
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &v); err != nil {
+		return v, fmt.Errorf("decode verdict: %w", err)
+	}
+	if v.Score < 1 || v.Score > 10 {
+		return v, fmt.Errorf("score %d out of range", v.Score)
+	}
+	return v, nil
+}
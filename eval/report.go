@@ -0,0 +1,136 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cencori/cencori-go"
+)
+
+// GraderSummary aggregates one grader's scores for a model.
+type GraderSummary struct {
+	// MeanScore is the average Score.Value over the graded outputs.
+	MeanScore float64
+	// PassRate is the fraction of graded outputs that passed.
+	PassRate float64
+	// Graded is the number of outputs scored; grader failures are excluded.
+	Graded int
+}
+
+// ModelSummary aggregates the results of one model.
+type ModelSummary struct {
+	Model   string
+	Cases   int
+	Errors  int
+	Graders map[string]GraderSummary
+	Usage   cencori.Usage
+	// Cost is the estimated total cost in USD, from Config.Pricing.
+	Cost        float64
+	MeanLatency time.Duration
+	P95Latency  time.Duration
+}
+
+// Report is the outcome of Run.
+type Report struct {
+	// Results holds every case for every model, grouped by model in
+	// Config.Models order.
+	Results []Result
+	// Models summarizes each model in Config.Models order.
+	Models []ModelSummary
+	// Graders are the grader names in Config.Graders order.
+	Graders []string
+}
+
+func newReport(cfg Config, results []Result) *Report {
+	r := &Report{Results: results}
+	for _, g := range cfg.Graders {
+		r.Graders = append(r.Graders, g.Name())
+	}
+	for _, model := range cfg.Models {
+		r.Models = append(r.Models, summarize(model, r.Graders, results))
+	}
+	return r
+}
+
+func summarize(model string, graders []string, results []Result) ModelSummary {
+	s := ModelSummary{Model: model, Graders: make(map[string]GraderSummary, len(graders))}
+	var latencies []time.Duration
+	for _, res := range results {
+		if res.Model != model {
+			continue
+		}
+		s.Cases++
+		if res.Err != nil {
+			s.Errors++
+			continue
+		}
+		latencies = append(latencies, res.Latency)
+		s.Usage.PromptTokens += res.Usage.PromptTokens
+		s.Usage.CompletionTokens += res.Usage.CompletionTokens
+		s.Usage.TotalTokens += res.Usage.TotalTokens
+		s.Cost += res.Cost
+	}
+
+	for _, name := range graders {
+		var gs GraderSummary
+		for _, res := range results {
+			score, ok := res.Scores[name]
+			if res.Model != model || !ok || score.Err != nil {
+				continue
+			}
+			gs.Graded++
+			gs.MeanScore += score.Value
+			if score.Pass {
+				gs.PassRate++
+			}
+		}
+		if gs.Graded > 0 {
+			gs.MeanScore /= float64(gs.Graded)
+			gs.PassRate /= float64(gs.Graded)
+		}
+		s.Graders[name] = gs
+	}
+
+	if len(latencies) > 0 {
+		var total time.Duration
+		for _, l := range latencies {
+			total += l
+		}
+		s.MeanLatency = total / time.Duration(len(latencies))
+		slices.Sort(latencies)
+		s.P95Latency = latencies[(len(latencies)*95+99)/100-1]
+	}
+	return s
+}
+
+// WriteTable writes a model comparison table to w, one row per model with
+// the pass rate of each grader.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := []string{"MODEL", "CASES", "ERRORS"}
+	for _, g := range r.Graders {
+		header = append(header, strings.ToUpper(g))
+	}
+	header = append(header, "MEAN LATENCY", "P95 LATENCY", "TOKENS", "COST (USD)")
+	fmt.Fprintln(tw, strings.Join(header, "\t")) //nolint:errcheck // The flush error is returned below.
+
+	for _, m := range r.Models {
+		row := []string{m.Model, fmt.Sprint(m.Cases), fmt.Sprint(m.Errors)}
+		for _, g := range r.Graders {
+			row = append(row, fmt.Sprintf("%.1f%%", m.Graders[g].PassRate*100))
+		}
+		row = append(row,
+			m.MeanLatency.Round(time.Millisecond).String(),
+			m.P95Latency.Round(time.Millisecond).String(),
+			fmt.Sprint(m.Usage.TotalTokens),
+			fmt.Sprintf("%.4f", m.Cost),
+		)
+		fmt.Fprintln(tw, strings.Join(row, "\t")) //nolint:errcheck // The flush error is returned below.
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,164 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateSchema checks a decoded JSON value against a decoded JSON Schema.
+// It supports the commonly used subset of the specification: type, enum,
+// const, properties, required, additionalProperties, items, minItems,
+// maxItems, minLength, maxLength, pattern, minimum and maximum. Other
+// keywords are ignored.
+func ValidateSchema(schema, value any) error {
+	return validate(schema, value, "$")
+}
+
+func validate(schema, value any, path string) error {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			return fmt.Errorf("%s: not allowed", path)
+		}
+		return nil
+	case map[string]any:
+		return validateObject(s, value, path)
+	default:
+		return fmt.Errorf("%s: invalid schema", path)
+	}
+}
+
+func validateObject(s map[string]any, value any, path string) error {
+	if t, ok := s["type"]; ok && !matchesType(t, value) {
+		return fmt.Errorf("%s: expected type %v, got %s", path, t, jsonType(value))
+	}
+	if enum, ok := s["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return reflect.DeepEqual(e, value) }) {
+		return fmt.Errorf("%s: value not in enum", path)
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, value) {
+		return fmt.Errorf("%s: value does not equal const", path)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return validateProperties(s, v, path)
+	case []any:
+		if n, ok := number(s["minItems"]); ok && float64(len(v)) < n {
+			return fmt.Errorf("%s: expected at least %v items", path, n)
+		}
+		if n, ok := number(s["maxItems"]); ok && float64(len(v)) > n {
+			return fmt.Errorf("%s: expected at most %v items", path, n)
+		}
+		if items, ok := s["items"]; ok {
+			for i, item := range v {
+				if err := validate(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if n, ok := number(s["minLength"]); ok && length < n {
+			return fmt.Errorf("%s: shorter than %v characters", path, n)
+		}
+		if n, ok := number(s["maxLength"]); ok && length > n {
+			return fmt.Errorf("%s: longer than %v characters", path, n)
+		}
+		if p, ok := s["pattern"].(string); ok {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("%s: invalid pattern: %w", path, err)
+			}
+			if !re.MatchString(v) {
+				return fmt.Errorf("%s: does not match pattern %s", path, p)
+			}
+		}
+	case float64:
+		if n, ok := number(s["minimum"]); ok && v < n {
+			return fmt.Errorf("%s: less than minimum %v", path, n)
+		}
+		if n, ok := number(s["maximum"]); ok && v > n {
+			return fmt.Errorf("%s: greater than maximum %v", path, n)
+		}
+	}
+	return nil
+}
+
+func validateProperties(s map[string]any, v map[string]any, path string) error {
+	if required, ok := s["required"].([]any); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, present := v[name]; !present {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+	}
+
+	props, _ := s["properties"].(map[string]any)
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		child := path + "." + k
+		if ps, ok := props[k]; ok {
+			if err := validate(ps, v[k], child); err != nil {
+				return err
+			}
+			continue
+		}
+		if additional, ok := s["additionalProperties"]; ok {
+			if err := validate(additional, v[k], child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func matchesType(t, value any) bool {
+	switch t := t.(type) {
+	case string:
+		got := jsonType(value)
+		return got == t || (t == "number" && got == "integer")
+	case []any:
+		return slices.ContainsFunc(t, func(one any) bool { return matchesType(one, value) })
+	default:
+		return true
+	}
+}
+
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return strings.ToLower(reflect.TypeOf(value).Kind().String())
+	}
+}
+
+func number(v any) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
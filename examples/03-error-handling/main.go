@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/cencori/cencori-go"
@@ -13,7 +12,7 @@ import (
 
 func main() {
 	client, err := cencori.NewClient(
-		cencori.WithAPIKey(os.Getenv("CENCORI_API_KEY")),
+		cencori.WithEnvDefaults(),
 	)
 	if err != nil {
 		log.Fatalf("Failed to create client: %v", err)
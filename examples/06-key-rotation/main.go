@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -11,75 +12,56 @@ import (
 )
 
 func main() {
+	dryRun := flag.Bool("dry-run", false, "show the keys that would be rotated without changing anything")
+	flag.Parse()
+
+	// The client reads the key from creds on every request, so it can be
+	// swapped while requests are in flight.
+	creds := cencori.NewRotatingCredentials(os.Getenv("CENCORI_API_KEY"))
 	client, err := cencori.NewClient(
-		cencori.WithAPIKey(os.Getenv("CENCORI_API_KEY")),
+		cencori.WithCredentials(creds),
 	)
 	if err != nil {
 		log.Fatalf("Failed to create client: %v", err)
 	}
 
 	projectID := "proj_123" // Your project ID
-	env := "production"
 
-	// 1. List existing keys
-	fmt.Println("Current API keys:")
-	keys, err := client.APIKeys.List(context.Background(), projectID, env)
-	if err != nil {
-		log.Fatalf("Failed to list keys: %v", err)
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
 
-	for _, key := range keys {
-		fmt.Printf("  - %s (created: %s, used: %d times)\n",
-			key.Name, key.CreatedAt.Format("2006-01-02"), key.UsageCount)
-	}
+	rotation, err := client.APIKeys.Rotate(ctx, projectID, cencori.RotateKeyParams{
+		Environment: "production",
+		Deploy: func(ctx context.Context, key *cencori.APIKey) error {
+			// In a real deployment: write key.Key to your secrets manager
+			// here so that other services pick it up, e.g. through
+			// cencori.NewFileCredentials on a mounted secret.
+			fmt.Printf("Deploying new key %s\n", key.ID)
 
-	fmt.Println("Creating new API key...")
-	newKey, err := client.APIKeys.Create(context.Background(), projectID, cencori.CreateAPIKeyParams{
-		Name:        "Production Key 2025-01",
-		Environment: env,
+			// Switch this process to the new key so that it keeps working
+			// once the old keys are revoked.
+			creds.Set(key.Key)
+			return nil
+		},
+		QuietPeriod:  30 * time.Minute,
+		PollInterval: time.Minute,
+		DryRun:       *dryRun,
+		// Re-running after an interruption resumes from this file
+		// instead of creating another key.
+		StateFile: "key-rotation.json",
 	})
 	if err != nil {
-		log.Fatalf("Failed to create key: %v", err)
+		log.Fatalf("Key rotation failed: %v", err)
 	}
 
-	// IMPORTANT: Save this key immediately - it's only shown once!
-	fmt.Printf("\n SAVE THIS KEY NOW:\n%s\n\n", newKey.Key)
-	fmt.Println("This is the only time you'll see the full key!")
-
-	// 3. Wait for deployment to use new key
-	fmt.Println("\n Waiting for new key to be deployed...")
-	fmt.Println("(In real scenario: update env vars, redeploy services)")
-	time.Sleep(2 * time.Second)
-
-	// 4. Verify old key usage has stopped
-	fmt.Println("\n Checking key usage...")
-	for _, oldKey := range keys {
-		stats, err := client.APIKeys.GetStats(context.Background(), projectID, oldKey.ID)
-		if err != nil {
-			log.Printf("Warning: couldn't get stats for %s: %v", oldKey.ID, err)
-			continue
-		}
-
-		fmt.Printf("Key %s:\n", oldKey.Name)
-		fmt.Printf("  Total requests: %d\n", stats.TotalRequests)
-		fmt.Printf("  Last used: %s\n", stats.LastUsedAt)
-
-		// If not used recently, safe to revoke
-		if time.Since(stats.LastUsedAt) > 48*time.Hour {
-			fmt.Printf("  Safe to revoke (not used in 48h)\n")
+	if *dryRun {
+		fmt.Println("Keys that would be rotated out:")
+		for _, key := range rotation.OldKeys {
+			fmt.Printf("  - %s (%s), last used %s\n",
+				key.Name, key.ID, rotation.LastActivity[key.ID].Format(time.RFC3339))
 		}
+		return
 	}
 
-	// 5. Revoke old keys
-	// Uncomment to actually revoke:
-	// fmt.Println("\n Revoking old keys...")
-	// for _, oldKey := range keys {
-	//     if err := client.APIKeys.Revoke(context.Background(), projectID, oldKey.ID); err != nil {
-	//         log.Printf("Failed to revoke %s: %v", oldKey.ID, err)
-	//         continue
-	//     }
-	//     fmt.Printf("  ✓ Revoked: %s\n", oldKey.Name)
-	// }
-
-	fmt.Println("\n Key rotation complete!")
+	fmt.Printf("Key rotation complete: new key %s, revoked %v\n", rotation.NewKey.ID, rotation.Revoked)
 }
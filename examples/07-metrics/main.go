@@ -4,14 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/cencori/cencori-go"
 )
 
 func main() {
 	client, err := cencori.NewClient(
-		cencori.WithAPIKey(os.Getenv("CENCORI_API_KEY")),
+		cencori.WithEnvDefaults(),
 	)
 	if err != nil {
 		log.Fatalf("Failed to create client: %v", err)
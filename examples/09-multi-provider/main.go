@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/cencori/cencori-go"
@@ -12,7 +12,7 @@ import (
 
 func main() {
 	client, err := cencori.NewClient(
-		cencori.WithAPIKey(os.Getenv("CENCORI_API_KEY")),
+		cencori.WithEnvDefaults(),
 	)
 	if err != nil {
 		log.Fatalf("Failed to create client: %v", err)
@@ -39,33 +39,40 @@ func main() {
 		err      error
 	}
 
-	results := make([]result, 0, len(models))
-
-	for _, model := range models {
-		start := time.Now()
-
-		resp, err := client.Chat.Create(context.Background(), &cencori.ChatParams{
+	// Send the prompt to every model concurrently; results keep model order
+	requests := make([]cencori.ChatParams, len(models))
+	for i, model := range models {
+		requests[i] = cencori.ChatParams{
 			Model: model,
 			Messages: []cencori.Message{
 				{Role: "user", Content: prompt},
 			},
-		})
+		}
+	}
 
-		latency := time.Since(start)
+	batch, err := client.Chat.CreateMany(context.Background(), cencori.CreateManyParams{
+		Requests:    requests,
+		Concurrency: len(models),
+	})
+	if err != nil {
+		log.Fatalf("Failed to query models: %v", err)
+	}
 
-		if err != nil {
+	results := make([]result, 0, len(models))
+	for i, r := range batch.Results {
+		if r.Err != nil {
 			results = append(results, result{
-				model: model,
-				err:   err,
+				model: models[i],
+				err:   r.Err,
 			})
 			continue
 		}
 
 		results = append(results, result{
-			model:    model,
-			response: resp.Choices[0].Message.Content,
-			tokens:   resp.Usage.TotalTokens,
-			latency:  latency,
+			model:    models[i],
+			response: r.Response.Choices[0].Message.Content,
+			tokens:   r.Response.Usage.TotalTokens,
+			latency:  r.Latency,
 		})
 	}
 
@@ -110,16 +117,5 @@ func main() {
 	if cheapest.model != "" {
 		fmt.Printf("Most efficient: %s (%d tokens)\n", cheapest.model, cheapest.tokens)
 	}
-}
-
-var strings = struct {
-	Repeat func(string, int) string
-}{
-	Repeat: func(s string, count int) string {
-		result := ""
-		for i := 0; i < count; i++ {
-			result += s
-		}
-		return result
-	},
+	fmt.Printf("Total tokens: %d across %d successful calls\n", batch.Usage.TotalTokens, len(models)-batch.Failed)
 }
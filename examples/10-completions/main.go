@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 
 	"github.com/cencori/cencori-go"
@@ -12,7 +11,7 @@ import (
 
 func main() {
 	client, err := cencori.NewClient(
-		cencori.WithAPIKey(os.Getenv("CENCORI_API_KEY")),
+		cencori.WithEnvDefaults(),
 	)
 	if err != nil {
 		log.Fatalf("Failed to create client: %v", err)
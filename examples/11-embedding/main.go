@@ -5,15 +5,15 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"os"
 	"strings"
 
 	"github.com/cencori/cencori-go"
+	"github.com/cencori/cencori-go/vectorstore"
 )
 
 func main() {
 	client, err := cencori.NewClient(
-		cencori.WithAPIKey(os.Getenv("CENCORI_API_KEY")),
+		cencori.WithEnvDefaults(),
 	)
 	if err != nil {
 		log.Fatalf("Failed to create client: %v", err)
@@ -24,7 +24,7 @@ func main() {
 	fmt.Println(repeat("=", 60))
 
 	resp, err := client.Chat.Embeddings(context.Background(), cencori.EmbeddingParams{
-		Input: "Hello, world!",
+		Input: cencori.EmbeddingText("Hello, world!"),
 		Model: "text-embedding-3-small",
 	})
 
@@ -55,7 +55,7 @@ func main() {
 	}
 
 	batchResp, err := client.Chat.Embeddings(context.Background(), cencori.EmbeddingParams{
-		Input: texts,
+		Input: cencori.EmbeddingTexts(texts),
 		Model: "text-embedding-3-small",
 	})
 
@@ -110,58 +110,59 @@ func main() {
 
 	query := "Tell me about programming languages"
 
-	// Embed all documents + query
-	allTexts := append([]string{query}, documents...)
-	searchResp, err := client.Chat.Embeddings(context.Background(), cencori.EmbeddingParams{
-		Input: allTexts,
+	// Embed the documents and index them
+	docResp, err := client.Chat.Embeddings(context.Background(), cencori.EmbeddingParams{
+		Input: cencori.EmbeddingTexts(documents),
 		Model: "text-embedding-3-small",
 	})
-
 	if err != nil {
-		log.Fatalf("Search embedding failed: %v", err)
+		log.Fatalf("Document embedding failed: %v", err)
 	}
 
-	queryEmbed := searchResp.Data[0].Embedding
-	fmt.Printf("Query: \"%s\"\n\n", query)
+	docs := make([]vectorstore.Document, len(documents))
+	for i, doc := range documents {
+		docs[i] = vectorstore.Document{ID: fmt.Sprintf("doc-%d", i), Content: doc}
+	}
 
-	// Calculate similarities
-	type result struct {
-		text  string
-		score float64
+	store := vectorstore.New(vectorstore.Options{Metric: vectorstore.Cosine})
+	if err := store.AddEmbeddings(docResp, docs); err != nil {
+		log.Fatalf("Indexing failed: %v", err)
 	}
 
-	results := make([]result, 0, len(documents))
-	for i, doc := range documents {
-		score := cosineSimilarity(queryEmbed, searchResp.Data[i+1].Embedding)
-		results = append(results, result{text: doc, score: score})
+	// Embed the query and search
+	queryResp, err := client.Chat.Embeddings(context.Background(), cencori.EmbeddingParams{
+		Input: cencori.EmbeddingText(query),
+		Model: "text-embedding-3-small",
+	})
+	if err != nil {
+		log.Fatalf("Query embedding failed: %v", err)
 	}
 
-	// Simple sort by score (descending)
-	for i := 0; i < len(results); i++ {
-		for j := i + 1; j < len(results); j++ {
-			if results[j].score > results[i].score {
-				results[i], results[j] = results[j], results[i]
-			}
-		}
+	fmt.Printf("Query: \"%s\"\n\n", query)
+
+	results, err := store.Search(queryResp.Data[0].Embedding, len(documents), nil)
+	if err != nil {
+		log.Fatalf("Search failed: %v", err)
 	}
 
 	fmt.Println("Ranked results:")
 	for i, r := range results {
-		fmt.Printf("%d. [%.4f] %s\n", i+1, r.score, r.text)
+		fmt.Printf("%d. [%.4f] %s\n", i+1, r.Score, r.Content)
 	}
 }
 
 // cosineSimilarity calculates cosine similarity between two vectors
-func cosineSimilarity(a, b []float64) float64 {
+func cosineSimilarity(a, b []float32) float64 {
 	if len(a) != len(b) {
 		return 0
 	}
 
 	var dotProduct, normA, normB float64
 	for i := range a {
-		dotProduct += a[i] * b[i]
-		normA += a[i] * a[i]
-		normB += b[i] * b[i]
+		x, y := float64(a[i]), float64(b[i])
+		dotProduct += x * y
+		normA += x * x
+		normB += y * y
 	}
 
 	if normA == 0 || normB == 0 {
@@ -0,0 +1,69 @@
+package cencori
+
+import (
+	"context"
+	"net/http"
+)
+
+// Hooks are callbacks invoked during the lifecycle of every API request, for
+// cross-cutting concerns such as audit logging or cost accounting. Any field
+// may be nil.
+//
+// params is the typed request body, such as *ChatParams, *EmbeddingParams or
+// *CreateProjectParams, and is nil for requests without one. result is the
+// decoded response, such as *ChatResponse. Hooks run synchronously, on the
+// streaming goroutine for streams, and must be safe for concurrent use.
+type Hooks struct {
+	// OnRequest is called before each HTTP attempt, including failover
+	// attempts. It may add headers to req.
+	OnRequest func(ctx context.Context, params any, req *http.Request)
+	// OnResponse is called after a successful response has been decoded;
+	// resp.Body has already been consumed. For streams it is called once the
+	// stream is established, with a nil result; chunks go to OnChunk.
+	OnResponse func(ctx context.Context, params any, resp *http.Response, result any)
+	// OnError is called when a request fails. resp is nil when no response
+	// was received.
+	OnError func(ctx context.Context, params any, resp *http.Response, err error)
+	// OnChunk is called for every chunk received from a stream, including a
+	// final chunk carrying an error.
+	OnChunk func(ctx context.Context, params *ChatParams, chunk StreamChunk)
+}
+
+// WithHooks registers request lifecycle hooks.
+func WithHooks(h Hooks) Option {
+	return func(c *ClientOptions) { c.Hooks = &h }
+}
+
+type hookParamsKey struct{}
+
+// withHookParams records params in ctx so that send can pass them to OnRequest.
+func (c *Client) withHookParams(ctx context.Context, params any) context.Context {
+	if c.hooks == nil || c.hooks.OnRequest == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, hookParamsKey{}, params)
+}
+
+func (c *Client) hookRequest(ctx context.Context, req *http.Request) {
+	if c.hooks != nil && c.hooks.OnRequest != nil {
+		c.hooks.OnRequest(ctx, ctx.Value(hookParamsKey{}), req)
+	}
+}
+
+func (c *Client) hookResponse(ctx context.Context, params any, resp *http.Response, result any) {
+	if c.hooks != nil && c.hooks.OnResponse != nil {
+		c.hooks.OnResponse(ctx, params, resp, result)
+	}
+}
+
+func (c *Client) hookError(ctx context.Context, params any, resp *http.Response, err error) {
+	if c.hooks != nil && c.hooks.OnError != nil {
+		c.hooks.OnError(ctx, params, resp, err)
+	}
+}
+
+func (c *Client) hookChunk(ctx context.Context, params *ChatParams, chunk StreamChunk) {
+	if c.hooks != nil && c.hooks.OnChunk != nil {
+		c.hooks.OnChunk(ctx, params, chunk)
+	}
+}
@@ -0,0 +1,118 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type hookLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *hookLog) add(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, fmt.Sprintf(format, args...))
+}
+
+func (l *hookLog) hooks() Hooks {
+	return Hooks{
+		OnRequest: func(ctx context.Context, params any, req *http.Request) {
+			req.Header.Set("X-Audit", "1")
+			l.add("request %T %s", params, req.URL.Path)
+		},
+		OnResponse: func(ctx context.Context, params any, resp *http.Response, result any) {
+			l.add("response %d %T", resp.StatusCode, result)
+		},
+		OnError: func(ctx context.Context, params any, resp *http.Response, err error) {
+			status := 0
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			l.add("error %d %v", status, errors.Is(err, ErrInvalidModel))
+		},
+		OnChunk: func(ctx context.Context, params *ChatParams, chunk StreamChunk) {
+			l.add("chunk %s", chunk.Choices[0].Delta.Content)
+		},
+	}
+}
+
+func TestHooks_Create(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Audit") != "1" {
+			t.Errorf("expected OnRequest to set headers")
+		}
+		var params ChatParams
+		json.NewDecoder(r.Body).Decode(&params)
+		if params.Model == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"bad model","code":"INVALID_MODEL"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-1"})
+	}))
+	defer server.Close()
+
+	var log hookLog
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithHooks(log.hooks()))
+
+	messages := []Message{{Role: "user", Content: "Hi"}}
+	if _, err := client.Chat.Create(context.Background(), &ChatParams{Model: "gpt-4o", Messages: messages}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	client.Chat.Create(context.Background(), &ChatParams{Model: "bad", Messages: messages})
+	client.Projects.List(context.Background(), "org")
+
+	want := []string{
+		"request *cencori.ChatParams /api/ai/chat",
+		"response 200 *cencori.ChatResponse",
+		"request *cencori.ChatParams /api/ai/chat",
+		"error 400 true",
+		"request <nil> /api/organizations/org/projects",
+	}
+	if len(log.events) < len(want) {
+		t.Fatalf("expected events %q, got %q", want, log.events)
+	}
+	for i, w := range want {
+		if log.events[i] != w {
+			t.Errorf("event %d: expected %q, got %q", i, w, log.events[i])
+		}
+	}
+}
+
+func TestHooks_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var log hookLog
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithHooks(log.hooks()))
+
+	chunks, err := client.Chat.Stream(context.Background(), &ChatParams{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for range chunks {
+	}
+
+	want := []string{
+		"request *cencori.ChatParams /api/ai/chat",
+		"response 200 <nil>",
+		"chunk Hel",
+		"chunk lo",
+	}
+	if fmt.Sprint(log.events) != fmt.Sprint(want) {
+		t.Errorf("expected events %q, got %q", want, log.events)
+	}
+}
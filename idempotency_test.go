@@ -0,0 +1,100 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKey_ReusedAcrossRetries(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		n := len(keys)
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(APIKey{ID: "key-1"})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond}),
+	)
+
+	if _, err := client.APIKeys.Create(context.Background(), "proj", CreateAPIKeyParams{Name: "ci"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if len(keys) != 2 || !uuid.MatchString(keys[0]) || keys[0] != keys[1] {
+		t.Errorf("expected one generated key reused for the retry, got %q", keys)
+	}
+}
+
+func TestIdempotencyKey_MutatingCallsOnly(t *testing.T) {
+	keys := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys[r.Method] = r.Header.Get("Idempotency-Key")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(Project{})
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	client.Projects.Get(ctx, "org", "proj")
+	client.Projects.Update(ctx, "org", "proj", CreateProjectParams{Name: "renamed"}, WithIdempotencyKey("rename-1"))
+	client.Projects.Delete(ctx, "org", "proj")
+
+	if keys[http.MethodGet] != "" {
+		t.Errorf("expected no key for GET, got %q", keys[http.MethodGet])
+	}
+	if keys[http.MethodPatch] != "rename-1" {
+		t.Errorf("expected the supplied key for PATCH, got %q", keys[http.MethodPatch])
+	}
+	if keys[http.MethodDelete] == "" {
+		t.Error("expected a generated key for DELETE")
+	}
+}
+
+func TestIdempotencyKey_NotSharedWithInternalRequests(t *testing.T) {
+	keys := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys[r.URL.Path] = r.Header.Get("Idempotency-Key")
+		if r.URL.Path == "/api/v1/moderations" {
+			json.NewEncoder(w).Encode(ModerationResponse{Results: []ModerationResult{{}}})
+			return
+		}
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123"})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithModeration(ModerationConfig{}))
+
+	params := &ChatParams{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "Hi"}}}
+	if _, err := client.Chat.Create(context.Background(), params, WithIdempotencyKey("chat-key")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if keys["/api/ai/chat"] != "chat-key" {
+		t.Errorf("expected the chat request to carry the caller's key, got %q", keys["/api/ai/chat"])
+	}
+	if got := keys["/api/v1/moderations"]; got == keys["/api/ai/chat"] {
+		t.Errorf("expected the moderation request to carry a different key, got %q", got)
+	}
+}
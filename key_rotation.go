@@ -0,0 +1,329 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// ErrKeysNotDrained is returned by APIKeysService.Rotate when old keys are
+// still in use after RotateKeyParams.DrainTimeout.
+var ErrKeysNotDrained = errors.New("cencori: old API keys are still in use")
+
+// RotationPhase is the progress of a key rotation.
+type RotationPhase string
+
+const (
+	// RotationPlanned is reported by dry runs; nothing has been changed.
+	RotationPlanned RotationPhase = "planned"
+	// RotationCreating means the new key is being created under
+	// KeyRotation.IdempotencyKey and may already exist.
+	RotationCreating RotationPhase = "creating"
+	// RotationCreated means the new key exists but has not been deployed.
+	RotationCreated RotationPhase = "created"
+	// RotationDeployed means the new key is deployed and old keys are draining.
+	RotationDeployed RotationPhase = "deployed"
+	// RotationDone means every old key has been revoked.
+	RotationDone RotationPhase = "done"
+)
+
+// RotateKeyParams configures APIKeysService.Rotate.
+type RotateKeyParams struct {
+	// Environment is the environment whose keys are rotated, e.g. "production".
+	Environment string
+	// Name is the name of the new key (default "<environment> key <date>").
+	Name string
+	// KeyIDs limits the old keys to rotate out (default: every key in the
+	// environment).
+	KeyIDs []string
+	// Deploy rolls the new key out to every consumer, for example by writing
+	// it to a secrets manager and calling RotatingCredentials.Set. It may be
+	// called again with the same key when an interrupted rotation resumes.
+	Deploy func(ctx context.Context, key *APIKey) error
+	// QuietPeriod is how long an old key must go unused before it is
+	// revoked (default 15m).
+	QuietPeriod time.Duration
+	// PollInterval is how often old key usage is checked (default 1m).
+	PollInterval time.Duration
+	// DrainTimeout bounds the wait for old keys to drain; zero waits until
+	// ctx is done.
+	DrainTimeout time.Duration
+	// DryRun reports the keys that would be rotated out and their last
+	// activity without creating, deploying or revoking anything.
+	DryRun bool
+	// StateFile persists progress after every step so that an interrupted
+	// rotation resumes where it stopped instead of creating another key.
+	// The file holds the new key's secret until it is deployed and is
+	// removed when the rotation is done.
+	StateFile string
+}
+
+func (p *RotateKeyParams) setDefaults() {
+	if p.Name == "" {
+		p.Name = fmt.Sprintf("%s key %s", p.Environment, time.Now().Format("2006-01-02"))
+	}
+	if p.QuietPeriod <= 0 {
+		p.QuietPeriod = 15 * time.Minute
+	}
+	if p.PollInterval <= 0 {
+		p.PollInterval = time.Minute
+	}
+}
+
+// KeyRotation is the state of a key rotation, as persisted to
+// RotateKeyParams.StateFile.
+type KeyRotation struct {
+	ProjectID   string        `json:"project_id"`
+	Environment string        `json:"environment"`
+	Phase       RotationPhase `json:"phase"`
+	// KeyName is the name of the new key.
+	KeyName string `json:"key_name,omitempty"`
+	// IdempotencyKey is sent when creating the new key, so that a resumed
+	// rotation gets the key created before the interruption.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// NewKey is the replacement key. Its secret is cleared once deployed.
+	NewKey *APIKey `json:"new_key,omitempty"`
+	// OldKeys are the keys being rotated out.
+	OldKeys []APIKey `json:"old_keys"`
+	// Revoked are the IDs of old keys that have been revoked.
+	Revoked []string `json:"revoked,omitempty"`
+	// LastActivity is the most recent use observed for each old key ID.
+	LastActivity map[string]time.Time `json:"last_activity,omitempty"`
+	// Requests is the request count last observed for each old key ID.
+	Requests map[string]int `json:"requests,omitempty"`
+}
+
+// Rotate replaces the API keys of an environment without downtime: it
+// creates a new key, hands it to params.Deploy, waits until each old key has
+// gone unused for params.QuietPeriod according to GetStats, and revokes it.
+//
+// The client must not authenticate with one of the keys being rotated out
+// unless Deploy switches it to the new key. A WithIdempotencyKey option is
+// ignored; each request Rotate sends gets its own key.
+func (s *APIKeysService) Rotate(ctx context.Context, projectID string, params RotateKeyParams, opts ...RequestOption) (*KeyRotation, error) {
+	ctx = withoutIdempotencyKey(withRequestOptions(ctx, opts))
+	if params.Environment == "" {
+		return nil, errors.New("cencori: rotate: environment is required")
+	}
+	if params.Deploy == nil && !params.DryRun {
+		return nil, errors.New("cencori: rotate: deploy callback is required")
+	}
+	params.setDefaults()
+
+	state, err := loadRotation(params.StateFile, projectID, params.Environment)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = &KeyRotation{ProjectID: projectID, Environment: params.Environment}
+		keys, err := s.List(ctx, projectID, params.Environment)
+		if err != nil {
+			return nil, fmt.Errorf("list keys: %w", err)
+		}
+		for _, k := range keys {
+			if len(params.KeyIDs) == 0 || slices.Contains(params.KeyIDs, k.ID) {
+				state.OldKeys = append(state.OldKeys, k)
+			}
+		}
+	}
+
+	if params.DryRun {
+		if state.Phase == "" {
+			state.Phase = RotationPlanned
+		}
+		for _, k := range state.pending() {
+			stats, err := s.GetStats(ctx, projectID, k.ID)
+			if err != nil {
+				return nil, fmt.Errorf("get stats for key %s: %w", k.ID, err)
+			}
+			state.observe(k.ID, stats, time.Now())
+		}
+		return state, nil
+	}
+
+	save := func() error { return saveRotation(params.StateFile, state) }
+
+	if state.NewKey == nil {
+		// Persist the idempotency key before creating, so that a rotation
+		// interrupted during Create gets the same key back on resume.
+		if state.IdempotencyKey == "" {
+			state.KeyName, state.IdempotencyKey = params.Name, newIdempotencyKey()
+			state.Phase = RotationCreating
+			if err := save(); err != nil {
+				return state, err
+			}
+		}
+		key, err := s.Create(ctx, projectID, CreateAPIKeyParams{Name: state.KeyName, Environment: params.Environment},
+			WithIdempotencyKey(state.IdempotencyKey))
+		if err != nil {
+			return nil, fmt.Errorf("create key: %w", err)
+		}
+		state.NewKey, state.Phase = key, RotationCreated
+		if err := save(); err != nil {
+			return state, err
+		}
+	}
+
+	if state.Phase == RotationCreated {
+		if err := params.Deploy(ctx, state.NewKey); err != nil {
+			return state, fmt.Errorf("deploy key: %w", err)
+		}
+		state.NewKey.Key = ""
+		state.Phase = RotationDeployed
+		if err := save(); err != nil {
+			return state, err
+		}
+	}
+
+	if err := s.drain(ctx, params, state, save); err != nil {
+		return state, err
+	}
+	state.Phase = RotationDone
+	if params.StateFile != "" {
+		if err := os.Remove(params.StateFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return state, fmt.Errorf("remove rotation state: %w", err)
+		}
+	}
+	return state, nil
+}
+
+// drain polls the old keys until each has been quiet for the quiet period
+// and revokes it.
+func (s *APIKeysService) drain(ctx context.Context, params RotateKeyParams, state *KeyRotation, save func() error) error {
+	var deadline <-chan time.Time
+	if params.DrainTimeout > 0 {
+		timer := time.NewTimer(params.DrainTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(params.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, k := range state.pending() {
+			stats, err := s.GetStats(ctx, state.ProjectID, k.ID)
+			if err != nil {
+				return fmt.Errorf("get stats for key %s: %w", k.ID, err)
+			}
+			now := time.Now()
+			if now.Sub(state.observe(k.ID, stats, now)) < params.QuietPeriod {
+				continue
+			}
+
+			if err := s.Revoke(ctx, state.ProjectID, k.ID); err != nil && !isNotFound(err) {
+				return fmt.Errorf("revoke key %s: %w", k.ID, err)
+			}
+			state.Revoked = append(state.Revoked, k.ID)
+			if err := save(); err != nil {
+				return err
+			}
+		}
+
+		pending := len(state.pending())
+		if pending == 0 {
+			return nil
+		}
+		if err := save(); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return fmt.Errorf("%w: %d of %d keys", ErrKeysNotDrained, pending, len(state.OldKeys))
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// pending returns the old keys that have not been revoked yet.
+func (r *KeyRotation) pending() []APIKey {
+	var out []APIKey
+	for _, k := range r.OldKeys {
+		if !slices.Contains(r.Revoked, k.ID) {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// observe records stats for an old key and returns its last activity: the
+// later of the reported last use and the last time its request count was
+// seen to grow.
+func (r *KeyRotation) observe(keyID string, stats *KeyUsageStats, now time.Time) time.Time {
+	if r.LastActivity == nil {
+		r.LastActivity = make(map[string]time.Time)
+		r.Requests = make(map[string]int)
+	}
+	last := r.LastActivity[keyID]
+	if stats.LastUsedAt.After(last) {
+		last = stats.LastUsedAt
+	}
+	if prev, seen := r.Requests[keyID]; seen && stats.TotalRequests > prev {
+		last = now
+	}
+	r.LastActivity[keyID] = last
+	r.Requests[keyID] = stats.TotalRequests
+	return last
+}
+
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// loadRotation reads an unfinished rotation from path. It returns nil if
+// path is empty or does not exist.
+func loadRotation(path, projectID, env string) (*KeyRotation, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read rotation state: %w", err)
+	}
+	var state KeyRotation
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decode rotation state: %w", err)
+	}
+	if state.ProjectID != projectID || state.Environment != env {
+		return nil, fmt.Errorf("cencori: rotation state in %s belongs to project %q environment %q", path, state.ProjectID, state.Environment)
+	}
+	return &state, nil
+}
+
+// saveRotation atomically writes state to path, readable by the owner only.
+func saveRotation(path string, state *KeyRotation) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode rotation state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("write rotation state: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // Fails harmlessly once the file has been renamed.
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck // The write error is reported instead.
+		return fmt.Errorf("write rotation state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write rotation state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write rotation state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// keyServer fakes the API key endpoints of one project. Keys listed in busy
+// report a use on every stats call. Creates are idempotent; with loseCreate
+// set the next create succeeds but its response is lost.
+type keyServer struct {
+	mu         sync.Mutex
+	keys       []APIKey
+	busy       map[string]bool
+	created    int
+	revoked    []string
+	lastUse    map[string]time.Time
+	count      map[string]int
+	idem       map[string]APIKey
+	revokeIdem []string
+	loseCreate bool
+}
+
+func newKeyServer(keys ...APIKey) *keyServer {
+	return &keyServer{keys: keys, busy: map[string]bool{}, lastUse: map[string]time.Time{}, count: map[string]int{}, idem: map[string]APIKey{}}
+}
+
+func (s *keyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/projects/proj/api-keys"), "/")
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 1:
+		json.NewEncoder(w).Encode(map[string]any{"keys": s.keys})
+	case r.Method == http.MethodPost:
+		idem := r.Header.Get("Idempotency-Key")
+		key, seen := s.idem[idem]
+		if !seen {
+			s.created++
+			key = APIKey{ID: "new", Key: "sk-new-secret", Environment: "production"}
+			s.keys = append(s.keys, key)
+			s.idem[idem] = key
+		}
+		if s.loseCreate {
+			s.loseCreate = false
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(key)
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "stats":
+		id := parts[1]
+		if s.busy[id] {
+			s.lastUse[id] = time.Now()
+			s.count[id]++
+		}
+		json.NewEncoder(w).Encode(KeyUsageStats{KeyID: id, TotalRequests: s.count[id], LastUsedAt: s.lastUse[id]})
+	case r.Method == http.MethodDelete:
+		s.revoked = append(s.revoked, parts[1])
+		s.revokeIdem = append(s.revokeIdem, r.Header.Get("Idempotency-Key"))
+		w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *keyServer) setBusy(id string, busy bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.busy[id] = busy
+}
+
+func rotationParams(deploy func(context.Context, *APIKey) error) RotateKeyParams {
+	return RotateKeyParams{
+		Environment:  "production",
+		Deploy:       deploy,
+		QuietPeriod:  50 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	}
+}
+
+func TestRotate_DrainsAndRevokes(t *testing.T) {
+	keys := newKeyServer(APIKey{ID: "idle"}, APIKey{ID: "busy"})
+	keys.lastUse["idle"] = time.Now().Add(-time.Hour)
+	keys.setBusy("busy", true)
+	server := httptest.NewServer(keys)
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("admin-key"), WithBaseURL(server.URL))
+	stateFile := filepath.Join(t.TempDir(), "rotation.json")
+
+	var deployed string
+	params := rotationParams(func(ctx context.Context, key *APIKey) error {
+		deployed = key.Key
+		// Consumers switch to the new key shortly after the deploy.
+		time.AfterFunc(30*time.Millisecond, func() { keys.setBusy("busy", false) })
+		return nil
+	})
+	params.StateFile = stateFile
+
+	state, err := client.APIKeys.Rotate(context.Background(), "proj", params)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if deployed != "sk-new-secret" {
+		t.Errorf("expected the new secret to be deployed, got %q", deployed)
+	}
+	if state.Phase != RotationDone || state.NewKey.ID != "new" || state.NewKey.Key != "" {
+		t.Errorf("unexpected final state: %+v", state)
+	}
+	if strings.Join(keys.revoked, ",") != "idle,busy" {
+		t.Errorf("expected the idle key revoked before the busy one, got %v", keys.revoked)
+	}
+	if _, err := os.Stat(stateFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the state file to be removed, got %v", err)
+	}
+}
+
+func TestRotate_ResumesAfterFailedDeploy(t *testing.T) {
+	keys := newKeyServer(APIKey{ID: "old"})
+	server := httptest.NewServer(keys)
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("admin-key"), WithBaseURL(server.URL))
+	stateFile := filepath.Join(t.TempDir(), "rotation.json")
+
+	params := rotationParams(func(context.Context, *APIKey) error { return errors.New("secrets manager down") })
+	params.StateFile = stateFile
+	if _, err := client.APIKeys.Rotate(context.Background(), "proj", params); err == nil {
+		t.Fatal("expected the deploy error")
+	}
+	if len(keys.revoked) != 0 {
+		t.Fatalf("expected nothing revoked before deploy, got %v", keys.revoked)
+	}
+
+	var deployed string
+	params.Deploy = func(_ context.Context, key *APIKey) error {
+		deployed = key.Key
+		return nil
+	}
+	if _, err := client.APIKeys.Rotate(context.Background(), "proj", params); err != nil {
+		t.Fatalf("expected the resumed rotation to succeed, got %v", err)
+	}
+
+	if keys.created != 1 {
+		t.Errorf("expected one key to be created, got %d", keys.created)
+	}
+	if deployed != "sk-new-secret" || len(keys.revoked) != 1 {
+		t.Errorf("expected the saved key deployed and the old key revoked, got %q, %v", deployed, keys.revoked)
+	}
+}
+
+func TestRotate_ResumesAfterInterruptedCreate(t *testing.T) {
+	keys := newKeyServer(APIKey{ID: "old-1"}, APIKey{ID: "old-2"})
+	keys.loseCreate = true
+	server := httptest.NewServer(keys)
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("admin-key"), WithBaseURL(server.URL))
+	params := rotationParams(func(context.Context, *APIKey) error { return nil })
+	params.StateFile = filepath.Join(t.TempDir(), "rotation.json")
+
+	noRetry := WithRequestRetryPolicy(RetryPolicy{})
+	if _, err := client.APIKeys.Rotate(context.Background(), "proj", params, noRetry); err == nil {
+		t.Fatal("expected the lost create response to fail the rotation")
+	}
+	if _, err := client.APIKeys.Rotate(context.Background(), "proj", params, WithIdempotencyKey("caller-key")); err != nil {
+		t.Fatalf("expected the resumed rotation to succeed, got %v", err)
+	}
+
+	if keys.created != 1 {
+		t.Errorf("expected the resumed rotation to reuse the created key, got %d keys", keys.created)
+	}
+	if len(keys.revokeIdem) != 2 || keys.revokeIdem[0] == keys.revokeIdem[1] || keys.revokeIdem[0] == "caller-key" {
+		t.Errorf("expected each revoke to carry its own idempotency key, got %v", keys.revokeIdem)
+	}
+}
+
+func TestRotate_DryRun(t *testing.T) {
+	keys := newKeyServer(APIKey{ID: "old"})
+	lastUse := time.Now().Add(-time.Hour).Truncate(time.Second)
+	keys.lastUse["old"] = lastUse
+	server := httptest.NewServer(keys)
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("admin-key"), WithBaseURL(server.URL))
+
+	state, err := client.APIKeys.Rotate(context.Background(), "proj", RotateKeyParams{Environment: "production", DryRun: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if keys.created != 0 || len(keys.revoked) != 0 {
+		t.Errorf("expected no changes, got %d created and %v revoked", keys.created, keys.revoked)
+	}
+	if state.Phase != RotationPlanned || len(state.OldKeys) != 1 || !state.LastActivity["old"].Equal(lastUse) {
+		t.Errorf("unexpected plan: %+v", state)
+	}
+}
+
+func TestRotate_DrainTimeout(t *testing.T) {
+	keys := newKeyServer(APIKey{ID: "busy"})
+	keys.setBusy("busy", true)
+	server := httptest.NewServer(keys)
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("admin-key"), WithBaseURL(server.URL))
+	stateFile := filepath.Join(t.TempDir(), "rotation.json")
+
+	params := rotationParams(func(context.Context, *APIKey) error { return nil })
+	params.StateFile = stateFile
+	params.DrainTimeout = 40 * time.Millisecond
+
+	_, err := client.APIKeys.Rotate(context.Background(), "proj", params)
+	if !errors.Is(err, ErrKeysNotDrained) {
+		t.Fatalf("expected ErrKeysNotDrained, got %v", err)
+	}
+
+	saved, err := loadRotation(stateFile, "proj", "production")
+	if err != nil || saved == nil {
+		t.Fatalf("expected saved state, got %v", err)
+	}
+	if saved.Phase != RotationDeployed || saved.NewKey.Key != "" {
+		t.Errorf("expected a deployed rotation without the secret, got %+v", saved)
+	}
+}
@@ -0,0 +1,198 @@
+package cencori
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultLogBodyBytes is the body logging limit used when WithBodyLogging is
+// given a non-positive size.
+const defaultLogBodyBytes = 4096
+
+// requestIDHeaders are the response headers the gateway may return the
+// request ID in, in order of preference.
+var requestIDHeaders = []string{"X-Request-Id", "X-Cencori-Request-Id"}
+
+// sensitiveHeaders are never logged in clear text.
+var sensitiveHeaders = []string{"CENCORI_API_KEY", "Authorization"}
+
+// secretBodyField is the JSON field that carries an API key's secret in
+// bodies sent to and received from the /api-keys endpoints.
+const secretBodyField = "key"
+
+// WithLogger logs the lifecycle of every API request to l: requests at debug
+// level, successful responses at debug level, error responses and endpoint
+// failover at warn level, and transport failures at error level. The API key
+// is redacted.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *ClientOptions) { c.Logger = l }
+}
+
+// WithBodyLogging also logs request and response bodies at debug level,
+// truncated to maxBytes (default 4 KiB). Streamed responses are not logged,
+// and the secrets in API key bodies are redacted. Bodies may still contain
+// sensitive data; enable this for debugging only.
+func WithBodyLogging(maxBytes int) Option {
+	return func(c *ClientOptions) {
+		if maxBytes <= 0 {
+			maxBytes = defaultLogBodyBytes
+		}
+		c.LogBodyBytes = maxBytes
+	}
+}
+
+type callInfoKey struct{}
+
+// callInfoFrom returns the CallInfo stored by startCall, if any.
+func callInfoFrom(ctx context.Context) *CallInfo {
+	info, _ := ctx.Value(callInfoKey{}).(*CallInfo) //nolint:errcheck // A missing value yields nil.
+	return info
+}
+
+// requestID returns the gateway request ID from response headers.
+func requestID(h http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := h.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// redactedHeaders renders h as a log group with sensitive values redacted.
+func redactedHeaders(h http.Header) slog.Attr {
+	attrs := make([]any, 0, len(h))
+	for name, values := range h {
+		value := strings.Join(values, ", ")
+		for _, s := range sensitiveHeaders {
+			if strings.EqualFold(name, s) {
+				value = "[REDACTED]"
+			}
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.Group("headers", attrs...)
+}
+
+// loggedBody returns body as it may be logged for a request to path. Secrets
+// in API key bodies are redacted; bodies that cannot be parsed are dropped.
+func loggedBody(path string, body []byte) []byte {
+	if !strings.Contains(path, "/api-keys") {
+		return body
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return []byte("[REDACTED]")
+	}
+	redactSecrets(v)
+	out, err := json.Marshal(v)
+	if err != nil {
+		return []byte("[REDACTED]")
+	}
+	return out
+}
+
+// redactSecrets replaces every secretBodyField string in v in place.
+func redactSecrets(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for name, field := range v {
+			if _, ok := field.(string); ok && name == secretBodyField {
+				v[name] = "[REDACTED]"
+				continue
+			}
+			redactSecrets(field)
+		}
+	case []any:
+		for _, elem := range v {
+			redactSecrets(elem)
+		}
+	}
+}
+
+// truncateBody returns at most n bytes of body as a string, cut at a rune boundary.
+func truncateBody(body []byte, n int) string {
+	if len(body) <= n {
+		return string(body)
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return string(body[:cut]) + "...(truncated)"
+}
+
+// requestAttrs are the attributes shared by every log record of an attempt.
+func requestAttrs(ctx context.Context, method, path string, attempt int, base string) []any {
+	attrs := []any{
+		slog.String("method", method),
+		slog.String("path", path),
+		slog.Int("attempt", attempt),
+		slog.String("base_url", base),
+	}
+	if info := callInfoFrom(ctx); info != nil && info.Model != "" {
+		attrs = append(attrs, slog.String("model", info.Model))
+	}
+	return attrs
+}
+
+func (c *Client) logRequest(ctx context.Context, req *http.Request, attrs []any, payload []byte) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs = append(attrs, redactedHeaders(req.Header))
+	if c.logBodyBytes > 0 && payload != nil {
+		attrs = append(attrs, slog.String("body", truncateBody(loggedBody(req.URL.Path, payload), c.logBodyBytes)))
+	}
+	c.logger.DebugContext(ctx, "cencori: request", attrs...)
+}
+
+// logResponse logs a response. When body logging is enabled it buffers the
+// body of non-streamed responses so it can be both logged and read by the caller.
+func (c *Client) logResponse(ctx context.Context, path string, resp *http.Response, attrs []any, latency time.Duration) {
+	if c.logger == nil {
+		return
+	}
+	attrs = append(attrs,
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("latency", latency),
+	)
+	if id := requestID(resp.Header); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+
+	level := slog.LevelDebug
+	if resp.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	if c.logBodyBytes > 0 && c.logger.Enabled(ctx, slog.LevelDebug) && !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		resp.Body.Close() //nolint:errcheck // The body has been read into memory.
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err == nil {
+			attrs = append(attrs, slog.String("body", truncateBody(loggedBody(path, body), c.logBodyBytes)))
+		}
+	}
+	c.logger.Log(ctx, level, "cencori: response", attrs...)
+}
+
+func (c *Client) logFailure(ctx context.Context, err error, attrs []any, latency time.Duration, failover bool) {
+	if c.logger == nil {
+		return
+	}
+	attrs = append(attrs, slog.Duration("latency", latency), slog.Any("error", err))
+	if failover {
+		c.logger.WarnContext(ctx, "cencori: endpoint unreachable, failing over", attrs...)
+		return
+	}
+	c.logger.ErrorContext(ctx, "cencori: request failed", attrs...)
+}
@@ -0,0 +1,124 @@
+package cencori
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for line := range strings.Lines(buf.String()) {
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestLogger_Lifecycle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-42")
+		if r.URL.Path == "/api/v1/metrics/24h" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"slow down","code":"RATE_LIMIT_EXCEEDED"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123", Model: strings.Repeat("m", 100)})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client, _ := NewClient(WithAPIKey("secret-key"), WithBaseURL(server.URL), WithLogger(logger), WithBodyLogging(20))
+
+	resp, err := client.Chat.Create(context.Background(), &ChatParams{
+		Model:    "gpt-4o",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.ID != "chat-123" {
+		t.Errorf("body logging must not consume the response, got %+v", resp)
+	}
+	client.Metrics.Get(context.Background(), "24h")
+
+	if strings.Contains(buf.String(), "secret-key") {
+		t.Fatal("the API key must never be logged")
+	}
+
+	records := logRecords(t, &buf)
+	if len(records) != 4 {
+		t.Fatalf("expected 4 log records, got %d:\n%s", len(records), buf.String())
+	}
+
+	req, res, failed := records[0], records[1], records[3]
+	if req["msg"] != "cencori: request" || req["model"] != "gpt-4o" || req["attempt"] != 1.0 {
+		t.Errorf("unexpected request record: %v", req)
+	}
+	if headers := req["headers"].(map[string]any); headers["Cencori_api_key"] != "[REDACTED]" {
+		t.Errorf("expected the API key header to be redacted, got %v", headers)
+	}
+	if body := req["body"].(string); !strings.HasSuffix(body, "...(truncated)") || len(body) != 20+len("...(truncated)") {
+		t.Errorf("expected a truncated request body, got %q", body)
+	}
+	if res["level"] != "DEBUG" || res["status"] != 200.0 || res["request_id"] != "req-42" {
+		t.Errorf("unexpected response record: %v", res)
+	}
+	if failed["level"] != "WARN" || failed["status"] != 429.0 || failed["path"] != "/api/v1/metrics/24h" {
+		t.Errorf("unexpected error response record: %v", failed)
+	}
+}
+
+func TestLogger_Failover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123"})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURLs(unreachableURL(), server.URL), WithLogger(logger))
+	defer client.Close()
+
+	if _, err := client.Chat.Create(context.Background(), &ChatParams{Model: "gpt-4o"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	records := logRecords(t, &buf)
+	if len(records) != 1 || records[0]["msg"] != "cencori: endpoint unreachable, failing over" || records[0]["attempt"] != 1.0 {
+		t.Errorf("expected a single failover warning, got:\n%s", buf.String())
+	}
+}
+
+func TestLogger_RedactsKeySecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(APIKey{ID: "key-1", Name: "ci", Key: "sk-new-secret"})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithLogger(logger), WithBodyLogging(0))
+
+	if _, err := client.APIKeys.Create(context.Background(), "proj", CreateAPIKeyParams{Name: "ci"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if strings.Contains(buf.String(), "sk-new-secret") {
+		t.Fatalf("the new key's secret must not be logged:\n%s", buf.String())
+	}
+	records := logRecords(t, &buf)
+	if len(records) != 2 || !strings.Contains(records[1]["body"].(string), `"key":"[REDACTED]"`) {
+		t.Errorf("expected the response body with the secret redacted, got:\n%s", buf.String())
+	}
+}
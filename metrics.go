@@ -14,7 +14,8 @@ type MetricsService struct {
 // Get retrieves metrics for the specified period.
 // It sends a GET request to the metrics API endpoint and returns the metrics response.
 // If the request fails or the context is cancelled, an error is returned.
-func (s *MetricsService) Get(ctx context.Context, period string) (*MetricsResponse, error) {
+func (s *MetricsService) Get(ctx context.Context, period string, opts ...RequestOption) (*MetricsResponse, error) {
+	ctx = withRequestOptions(ctx, opts)
 	path := fmt.Sprintf("/api/v1/metrics/%s", period)
 	return doRequest[any, MetricsResponse](s.client, ctx, "GET", path, nil)
 }
@@ -0,0 +1,79 @@
+package cencori
+
+import "context"
+
+// CreateFunc is the signature of ChatService.Create.
+type CreateFunc func(ctx context.Context, params *ChatParams) (*ChatResponse, error)
+
+// StreamFunc is the signature of ChatService.Stream.
+type StreamFunc func(ctx context.Context, params *ChatParams) (<-chan StreamChunk, error)
+
+// EmbeddingsFunc is the signature of ChatService.Embeddings.
+type EmbeddingsFunc func(ctx context.Context, params EmbeddingParams) (*EmbeddingResponse, error)
+
+// Middleware intercepts ChatService calls. Each field wraps the next handler
+// in the chain; nil fields pass calls through unchanged. Middleware registered
+// first runs outermost.
+type Middleware struct {
+	Create     func(next CreateFunc) CreateFunc
+	Stream     func(next StreamFunc) StreamFunc
+	Embeddings func(next EmbeddingsFunc) EmbeddingsFunc
+}
+
+// WithMiddleware installs middleware around Chat.Create, Chat.Stream and Chat.Embeddings.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *ClientOptions) { c.Middleware = append(c.Middleware, mw...) }
+}
+
+// Use appends middleware to an existing client. It is meant for middleware
+// that needs the client itself, such as a SemanticCache, and must be called
+// before the client is used concurrently.
+func (c *Client) Use(mw ...Middleware) {
+	c.middleware = append(c.middleware, mw...)
+}
+
+type clientKey struct{}
+
+// withClient records c as the client serving the call in ctx, for middleware
+// that may be shared between clients, such as SemanticCache.
+func (c *Client) withClient(ctx context.Context) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+func clientFrom(ctx context.Context) *Client {
+	c, _ := ctx.Value(clientKey{}).(*Client) //nolint:errcheck // A missing value yields nil.
+	return c
+}
+
+func (c *Client) wrapCreate(h CreateFunc) CreateFunc {
+	if c.moderation != nil {
+		h = c.moderation.Create(h)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		if mw := c.middleware[i].Create; mw != nil {
+			h = mw(h)
+		}
+	}
+	return h
+}
+
+func (c *Client) wrapStream(h StreamFunc) StreamFunc {
+	if c.moderation != nil {
+		h = c.moderation.Stream(h)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		if mw := c.middleware[i].Stream; mw != nil {
+			h = mw(h)
+		}
+	}
+	return h
+}
+
+func (c *Client) wrapEmbeddings(h EmbeddingsFunc) EmbeddingsFunc {
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		if mw := c.middleware[i].Embeddings; mw != nil {
+			h = mw(h)
+		}
+	}
+	return h
+}
@@ -0,0 +1,46 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware_Order(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatParams
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(ChatResponse{ID: req.Messages[0].Content})
+	}))
+	defer server.Close()
+
+	tag := func(name string) Middleware {
+		return Middleware{
+			Create: func(next CreateFunc) CreateFunc {
+				return func(ctx context.Context, params *ChatParams) (*ChatResponse, error) {
+					params.Messages[0].Content += name
+					return next(ctx, params)
+				}
+			},
+		}
+	}
+
+	client, _ := NewClient(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithMiddleware(tag("a"), Middleware{}),
+	)
+	client.Use(tag("b"))
+
+	resp, err := client.Chat.Create(context.Background(), &ChatParams{
+		Messages: []Message{{Role: "user", Content: ">"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.ID != ">ab" {
+		t.Errorf("expected middleware to run in registration order, got %q", resp.ID)
+	}
+}
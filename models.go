@@ -42,6 +42,9 @@ type ChatResponse struct {
 		FinishReason string  `json:"finish_reason"`
 	} `json:"choices"`
 	Usage Usage `json:"usage"`
+
+	// CacheHit is true when the response was served from the client cache.
+	CacheHit bool `json:"-"`
 }
 
 // Completions Models.
@@ -54,16 +57,28 @@ type CompletionParams struct {
 
 // Embedding Models.
 type EmbeddingParams struct {
-	Input any    `json:"input"` // string or []string
-	Model string `json:"model"`
+	Input          EmbeddingInput `json:"input"`
+	Model          string         `json:"model"`
+	Dimensions     *int           `json:"dimensions,omitempty"`
+	EncodingFormat EncodingFormat `json:"encoding_format,omitempty"`
+	User           *string        `json:"user,omitempty"`
 }
 
+// EncodingFormat selects how embeddings are sent over the wire. Both formats
+// decode into EmbeddingData.Embedding; base64 is roughly a quarter of the size.
+type EncodingFormat string
+
+const (
+	EncodingFloat  EncodingFormat = "float"
+	EncodingBase64 EncodingFormat = "base64"
+)
+
 type EmbeddingUsage struct {
 	TotalTokens int `json:"total_tokens"`
 }
 
 type EmbeddingData struct {
-	Embedding []float64 `json:"embedding"`
+	Embedding []float32 `json:"embedding"`
 	Index     int       `json:"index"`
 }
 
@@ -72,6 +87,9 @@ type EmbeddingResponse struct {
 	Data   []EmbeddingData `json:"data"`
 	Usage  EmbeddingUsage  `json:"usage"`
 	Object string          `json:"object"`
+
+	// CacheHit is true when the response was served from the client cache.
+	CacheHit bool `json:"-"`
 }
 
 // Stream Response.
@@ -95,6 +113,99 @@ type StreamDelta struct {
 	Content string `json:"content,omitempty"`
 }
 
+// Moderation Models.
+type ModerationInput struct {
+	Type     string           `json:"type"`
+	Text     string           `json:"text,omitempty"`
+	ImageURL *ModerationImage `json:"image_url,omitempty"`
+}
+
+type ModerationImage struct {
+	URL string `json:"url"`
+}
+
+// ModerationText classifies a piece of text.
+func ModerationText(text string) ModerationInput {
+	return ModerationInput{Type: "text", Text: text}
+}
+
+// ModerationImageURL classifies an image given by URL or data URI.
+func ModerationImageURL(url string) ModerationInput {
+	return ModerationInput{Type: "image_url", ImageURL: &ModerationImage{URL: url}}
+}
+
+type ModerationParams struct {
+	Model string            `json:"model,omitempty"`
+	Input []ModerationInput `json:"input"`
+}
+
+type ModerationResult struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
+
+type ModerationResponse struct {
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Results []ModerationResult `json:"results"`
+}
+
+// Batch Models.
+type BatchStatus string
+
+const (
+	BatchValidating BatchStatus = "validating"
+	BatchInProgress BatchStatus = "in_progress"
+	BatchCompleted  BatchStatus = "completed"
+	BatchFailed     BatchStatus = "failed"
+	BatchCancelled  BatchStatus = "cancelled"
+	BatchExpired    BatchStatus = "expired"
+)
+
+// Done reports whether the batch has reached a terminal status.
+func (s BatchStatus) Done() bool {
+	switch s {
+	case BatchCompleted, BatchFailed, BatchCancelled, BatchExpired:
+		return true
+	case BatchValidating, BatchInProgress:
+	}
+	return false
+}
+
+// BatchRequest is one line of a batch input file.
+type BatchRequest struct {
+	CustomID string     `json:"custom_id"`
+	Params   ChatParams `json:"params"`
+}
+
+// BatchResult is one line of a batch output file. Exactly one of Response and Error is set.
+type BatchResult struct {
+	CustomID string        `json:"custom_id"`
+	Response *ChatResponse `json:"response,omitempty"`
+	Error    *APIError     `json:"error,omitempty"`
+}
+
+type BatchRequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+type Batch struct {
+	ID            string             `json:"id"`
+	Status        BatchStatus        `json:"status"`
+	RequestCounts BatchRequestCounts `json:"request_counts"`
+	CreatedAt     time.Time          `json:"created_at"`
+	CompletedAt   *time.Time         `json:"completed_at,omitempty"`
+	Metadata      map[string]string  `json:"metadata,omitempty"`
+}
+
+type CreateBatchParams struct {
+	Requests []BatchRequest    `json:"requests"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
 // --- Project Models ---.
 type Project struct {
 	ID          string    `json:"id"`
@@ -0,0 +1,167 @@
+package cencori
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ModerationService classifies text and images into content categories.
+// It uses a Client to communicate with the moderation API endpoint.
+type ModerationService struct {
+	client *Client
+}
+
+// Create classifies each input separately; Results[i] belongs to params.Input[i].
+func (s *ModerationService) Create(ctx context.Context, params ModerationParams, opts ...RequestOption) (*ModerationResponse, error) {
+	ctx = withRequestOptions(ctx, opts)
+	if len(params.Input) == 0 {
+		return nil, errors.New("cencori: moderation input is empty")
+	}
+	resp, err := doRequest[ModerationParams, ModerationResponse](s.client, ctx, "POST", "/api/v1/moderations", &params)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(params.Input) {
+		return nil, fmt.Errorf("expected %d moderation results, got %d", len(params.Input), len(resp.Results))
+	}
+	return resp, nil
+}
+
+// FlaggedCategories returns the flagged categories of r, sorted.
+func (r ModerationResult) FlaggedCategories() []string {
+	var flagged []string
+	for category, ok := range r.Categories {
+		if ok {
+			flagged = append(flagged, category)
+		}
+	}
+	slices.Sort(flagged)
+	return flagged
+}
+
+// ModerationConfig configures the moderation pre-flight check on Chat.Create
+// and Chat.Stream.
+type ModerationConfig struct {
+	// Model is the moderation model (default: the server's default).
+	Model string
+	// Roles lists the message roles that are screened (default: "user").
+	Roles []string
+	// Threshold, if positive, flags a message when any category scores at or
+	// above it instead of relying on the service's verdict.
+	Threshold float64
+	// Categories, if set, restricts flagging to these categories.
+	Categories []string
+	// FailOpen lets requests through when the moderation call itself fails.
+	// By default such requests fail with the moderation error.
+	FailOpen bool
+}
+
+// ModerationError is returned by the moderation pre-flight check when a
+// message is flagged. It matches ErrContentFiltered with errors.Is.
+type ModerationError struct {
+	// MessageIndex is the index of the flagged message in ChatParams.Messages.
+	MessageIndex int
+	// Categories are the categories the message was flagged for.
+	Categories []string
+	Result     ModerationResult
+}
+
+func (e *ModerationError) Error() string {
+	return fmt.Sprintf("cencori: message %d flagged by moderation (categories: %s)", e.MessageIndex, strings.Join(e.Categories, ", "))
+}
+
+func (e *ModerationError) Unwrap() error {
+	return ErrContentFiltered
+}
+
+// WithModeration screens chat messages with the moderation service before
+// Chat.Create and Chat.Stream send them, failing flagged requests locally
+// with a *ModerationError.
+func WithModeration(cfg ModerationConfig) Option {
+	return func(c *ClientOptions) { c.Moderation = &cfg }
+}
+
+// Middleware returns the moderation pre-flight check as middleware.
+// WithModeration runs it inside all other middleware, including middleware
+// added with Client.Use, so that it screens messages as rewritten by
+// middleware such as Redactor.
+func (s *ModerationService) Middleware(cfg ModerationConfig) Middleware {
+	if len(cfg.Roles) == 0 {
+		cfg.Roles = []string{"user"}
+	}
+	return Middleware{
+		Create: func(next CreateFunc) CreateFunc {
+			return func(ctx context.Context, params *ChatParams) (*ChatResponse, error) {
+				if err := s.screen(ctx, cfg, params.Messages); err != nil {
+					return nil, err
+				}
+				return next(ctx, params)
+			}
+		},
+		Stream: func(next StreamFunc) StreamFunc {
+			return func(ctx context.Context, params *ChatParams) (<-chan StreamChunk, error) {
+				if err := s.screen(ctx, cfg, params.Messages); err != nil {
+					return nil, err
+				}
+				return next(ctx, params)
+			}
+		},
+	}
+}
+
+// screen moderates the messages with a screened role in a single call and
+// returns a *ModerationError for the first flagged one.
+func (s *ModerationService) screen(ctx context.Context, cfg ModerationConfig, messages []Message) error {
+	var indexes []int
+	params := ModerationParams{Model: cfg.Model}
+	for i, m := range messages {
+		if slices.Contains(cfg.Roles, m.Role) && strings.TrimSpace(m.Content) != "" {
+			indexes = append(indexes, i)
+			params.Input = append(params.Input, ModerationText(m.Content))
+		}
+	}
+	if len(params.Input) == 0 {
+		return nil
+	}
+
+	resp, err := s.Create(forInternalRequest(ctx), params)
+	if err != nil {
+		if cfg.FailOpen && ctx.Err() == nil {
+			return nil
+		}
+		return fmt.Errorf("moderation: %w", err)
+	}
+
+	for i, r := range resp.Results {
+		if categories := cfg.flagged(r); len(categories) > 0 {
+			return &ModerationError{MessageIndex: indexes[i], Categories: categories, Result: r}
+		}
+	}
+	return nil
+}
+
+// flagged returns the categories r is flagged for under cfg.
+func (cfg ModerationConfig) flagged(r ModerationResult) []string {
+	var categories []string
+	if cfg.Threshold > 0 {
+		for category, score := range r.CategoryScores {
+			if score >= cfg.Threshold {
+				categories = append(categories, category)
+			}
+		}
+		slices.Sort(categories)
+	} else if r.Flagged {
+		categories = r.FlaggedCategories()
+		if len(categories) == 0 {
+			categories = []string{"unspecified"}
+		}
+	}
+
+	if len(cfg.Categories) > 0 {
+		categories = slices.DeleteFunc(categories, func(c string) bool { return !slices.Contains(cfg.Categories, c) })
+	}
+	return categories
+}
@@ -0,0 +1,178 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// moderationServer flags text containing "attack" for violence and scores
+// text containing "rude" 0.6 for harassment without flagging it.
+func moderationServer(t *testing.T, chatCalls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/ai/chat" {
+			chatCalls.Add(1)
+			json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123"})
+			return
+		}
+
+		var params ModerationParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		resp := ModerationResponse{ID: "modr-1"}
+		for _, in := range params.Input {
+			result := ModerationResult{
+				Categories:     map[string]bool{"violence": false, "harassment": false},
+				CategoryScores: map[string]float64{"violence": 0.01, "harassment": 0.01},
+			}
+			if in.Type == "text" && strings.Contains(in.Text, "attack") {
+				result.Flagged = true
+				result.Categories["violence"] = true
+				result.CategoryScores["violence"] = 0.9
+			}
+			if in.Type == "text" && strings.Contains(in.Text, "rude") {
+				result.CategoryScores["harassment"] = 0.6
+			}
+			resp.Results = append(resp.Results, result)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestModeration_Create(t *testing.T) {
+	var chatCalls atomic.Int32
+	server := moderationServer(t, &chatCalls)
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	resp, err := client.Moderation.Create(context.Background(), ModerationParams{
+		Input: []ModerationInput{
+			ModerationText("hello"),
+			ModerationText("attack!"),
+			ModerationImageURL("https://example.com/cat.png"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(resp.Results) != 3 || resp.Results[0].Flagged || !resp.Results[1].Flagged {
+		t.Fatalf("unexpected results: %+v", resp.Results)
+	}
+	if got := resp.Results[1].FlaggedCategories(); len(got) != 1 || got[0] != "violence" {
+		t.Errorf("expected [violence], got %v", got)
+	}
+}
+
+func TestModeration_PreFlight(t *testing.T) {
+	var chatCalls atomic.Int32
+	server := moderationServer(t, &chatCalls)
+	defer server.Close()
+
+	messages := func(content string) *ChatParams {
+		return &ChatParams{Model: "gpt-4o", Messages: []Message{
+			{Role: "system", Content: "attack is fine in system prompts"},
+			{Role: "user", Content: "Hi"},
+			{Role: "user", Content: content},
+		}}
+	}
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithModeration(ModerationConfig{}))
+
+	if _, err := client.Chat.Create(context.Background(), messages("hello")); err != nil {
+		t.Fatalf("expected clean input to pass, got %v", err)
+	}
+
+	_, err := client.Chat.Create(context.Background(), messages("plan an attack"))
+	var modErr *ModerationError
+	if !errors.Is(err, ErrContentFiltered) || !errors.As(err, &modErr) {
+		t.Fatalf("expected a ModerationError, got %v", err)
+	}
+	if modErr.MessageIndex != 2 || modErr.Categories[0] != "violence" {
+		t.Errorf("unexpected moderation error: %+v", modErr)
+	}
+
+	if _, err := client.Chat.Stream(context.Background(), messages("plan an attack")); !errors.Is(err, ErrContentFiltered) {
+		t.Errorf("expected Stream to be screened, got %v", err)
+	}
+	if chatCalls.Load() != 1 {
+		t.Errorf("expected flagged requests not to reach the chat endpoint, got %d calls", chatCalls.Load())
+	}
+
+	strict, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL),
+		WithModeration(ModerationConfig{Threshold: 0.5, Categories: []string{"harassment"}}))
+	if _, err := strict.Chat.Create(context.Background(), messages("you are rude")); !errors.Is(err, ErrContentFiltered) {
+		t.Errorf("expected the threshold to flag harassment, got %v", err)
+	}
+	if _, err := strict.Chat.Create(context.Background(), messages("plan an attack")); err != nil {
+		t.Errorf("expected violence to be ignored, got %v", err)
+	}
+}
+
+func TestModeration_FailOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/ai/chat" {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "down"})
+			return
+		}
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123"})
+	}))
+	defer server.Close()
+
+	params := &ChatParams{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "Hi"}}}
+
+	closed, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithModeration(ModerationConfig{}))
+	if _, err := closed.Chat.Create(context.Background(), params); err == nil {
+		t.Error("expected the request to fail when moderation is down")
+	}
+
+	open, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithModeration(ModerationConfig{FailOpen: true}))
+	if _, err := open.Chat.Create(context.Background(), params); err != nil {
+		t.Errorf("expected FailOpen to let the request through, got %v", err)
+	}
+}
+
+func TestModeration_AfterRedaction(t *testing.T) {
+	var moderated []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/ai/chat" {
+			json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123"})
+			return
+		}
+		var params ModerationParams
+		json.NewDecoder(r.Body).Decode(&params)
+		resp := ModerationResponse{ID: "modr-1"}
+		for _, in := range params.Input {
+			moderated = append(moderated, in.Text)
+			resp.Results = append(resp.Results, ModerationResult{})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	redactor := NewRedactor(RedactionConfig{})
+	withOption, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL),
+		WithModeration(ModerationConfig{}), WithMiddleware(redactor.Middleware()))
+	withUse, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithModeration(ModerationConfig{}))
+	withUse.Use(redactor.Middleware())
+
+	for name, client := range map[string]*Client{"WithMiddleware": withOption, "Use": withUse} {
+		moderated = nil
+		params := &ChatParams{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "Email jane@example.com"}}}
+		if _, err := client.Chat.Create(context.Background(), params); err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if _, err := client.Chat.Stream(context.Background(), params); err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if len(moderated) != 2 || moderated[0] != "Email [EMAIL_1]" || moderated[1] != "Email [EMAIL_1]" {
+			t.Errorf("%s: expected redacted text to be moderated, got %q", name, moderated)
+		}
+	}
+}
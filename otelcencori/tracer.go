@@ -0,0 +1,156 @@
+// Package otelcencori instruments a cencori Client with OpenTelemetry. Each
+// API call becomes a client span carrying the gen_ai.* semantic convention
+// attributes, streams record the time to first token, and the trace context
+// is propagated to the gateway in the request headers.
+//
+//	client, err := cencori.NewClient(
+//		cencori.WithAPIKey(key),
+//		cencori.WithTracer(otelcencori.NewTracer()),
+//	)
+package otelcencori
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cencori/cencori-go"
+)
+
+const instrumentationName = "github.com/cencori/cencori-go/otelcencori"
+
+// Option configures the Tracer.
+type Option func(*Tracer)
+
+// WithTracerProvider sets the provider spans are created with (default: the global provider).
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(t *Tracer) { t.provider = tp }
+}
+
+// WithPropagator sets the propagator used to inject trace context into
+// request headers (default: the global propagator).
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(t *Tracer) { t.propagator = p }
+}
+
+// Tracer implements cencori.Tracer with OpenTelemetry.
+type Tracer struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+	tracer     trace.Tracer
+}
+
+// NewTracer creates a Tracer.
+func NewTracer(opts ...Option) *Tracer {
+	t := &Tracer{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.provider == nil {
+		t.provider = otel.GetTracerProvider()
+	}
+	if t.propagator == nil {
+		t.propagator = otel.GetTextMapPropagator()
+	}
+	t.tracer = t.provider.Tracer(instrumentationName)
+	return t
+}
+
+// Start implements cencori.Tracer.
+func (t *Tracer) Start(ctx context.Context, call cencori.CallInfo) (context.Context, cencori.CallSpan) {
+	name := call.Method + " " + call.Path
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", call.Method),
+		attribute.String("url.path", call.Path),
+	}
+	if call.Operation != "" {
+		name = call.Operation
+		attrs = append(attrs, attribute.String("gen_ai.operation.name", call.Operation))
+		if call.Model != "" {
+			name += " " + call.Model
+			attrs = append(attrs,
+				attribute.String("gen_ai.system", system(call.Model)),
+				attribute.String("gen_ai.request.model", call.Model),
+			)
+		}
+		if call.Stream {
+			attrs = append(attrs, attribute.Bool("cencori.stream", true))
+		}
+	}
+
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx, &callSpan{ctx: ctx, span: span, propagator: t.propagator, start: time.Now()}
+}
+
+// system maps a model to its gen_ai.system value.
+func system(model string) string {
+	switch provider := cencori.ProviderKey(model); provider {
+	case "google":
+		return "gcp.gemini"
+	default:
+		return provider
+	}
+}
+
+type callSpan struct {
+	ctx        context.Context
+	span       trace.Span
+	propagator propagation.TextMapPropagator
+	start      time.Time
+}
+
+func (s *callSpan) Inject(header http.Header) {
+	s.propagator.Inject(s.ctx, propagation.HeaderCarrier(header))
+}
+
+func (s *callSpan) FirstToken() {
+	ttft := time.Since(s.start)
+	s.span.AddEvent("gen_ai.first_token")
+	s.span.SetAttributes(attribute.Float64("cencori.time_to_first_token", ttft.Seconds()))
+}
+
+func (s *callSpan) End(result cencori.CallResult) {
+	var attrs []attribute.KeyValue
+	if result.StatusCode != 0 {
+		attrs = append(attrs, attribute.Int("http.response.status_code", result.StatusCode))
+	}
+	if result.ResponseID != "" {
+		attrs = append(attrs, attribute.String("gen_ai.response.id", result.ResponseID))
+	}
+	if result.ResponseModel != "" {
+		attrs = append(attrs, attribute.String("gen_ai.response.model", result.ResponseModel))
+	}
+	if result.Usage.PromptTokens > 0 {
+		attrs = append(attrs, attribute.Int("gen_ai.usage.input_tokens", result.Usage.PromptTokens))
+	}
+	if result.Usage.CompletionTokens > 0 {
+		attrs = append(attrs, attribute.Int("gen_ai.usage.output_tokens", result.Usage.CompletionTokens))
+	}
+	if len(result.FinishReasons) > 0 {
+		attrs = append(attrs, attribute.StringSlice("gen_ai.response.finish_reasons", result.FinishReasons))
+	}
+
+	if result.Err != nil {
+		attrs = append(attrs, attribute.String("error.type", errorType(result.Err)))
+		s.span.RecordError(result.Err)
+		s.span.SetStatus(codes.Error, result.Err.Error())
+	}
+	s.span.SetAttributes(attrs...)
+	s.span.End()
+}
+
+// errorType returns the gateway error code, or "_OTHER" for errors without one.
+func errorType(err error) string {
+	var apiErr *cencori.APIError
+	if errors.As(err, &apiErr) && apiErr.Code != "" {
+		return apiErr.Code
+	}
+	return "_OTHER"
+}
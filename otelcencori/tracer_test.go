@@ -0,0 +1,142 @@
+package otelcencori
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/cencori/cencori-go"
+)
+
+func newClient(t *testing.T, handler http.HandlerFunc) (*cencori.Client, *tracetest.SpanRecorder) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewTracer(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		WithPropagator(propagation.TraceContext{}),
+	)
+	client, err := cencori.NewClient(cencori.WithAPIKey("test-key"), cencori.WithBaseURL(server.URL), cencori.WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return client, recorder
+}
+
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestTracer_Create(t *testing.T) {
+	var traceparent string
+	client, recorder := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		fmt.Fprint(w, `{"id":"chat-1","model":"gpt-4o-2024-08-06","choices":[{"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],`+
+			`"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`)
+	})
+
+	_, err := client.Chat.Create(context.Background(), &cencori.ChatParams{
+		Model:    "gpt-4o",
+		Messages: []cencori.Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "chat gpt-4o" {
+		t.Errorf("unexpected span name %q", span.Name())
+	}
+	if want := fmt.Sprintf("00-%s-%s-01", span.SpanContext().TraceID(), span.SpanContext().SpanID()); traceparent != want {
+		t.Errorf("expected traceparent %q, got %q", want, traceparent)
+	}
+
+	a := attrs(span)
+	for key, want := range map[attribute.Key]any{
+		"gen_ai.operation.name":      "chat",
+		"gen_ai.system":              "openai",
+		"gen_ai.request.model":       "gpt-4o",
+		"gen_ai.response.model":      "gpt-4o-2024-08-06",
+		"gen_ai.response.id":         "chat-1",
+		"gen_ai.usage.input_tokens":  int64(5),
+		"gen_ai.usage.output_tokens": int64(2),
+		"http.response.status_code":  int64(200),
+	} {
+		if got := a[key].AsInterface(); got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+	if reasons := a["gen_ai.response.finish_reasons"].AsStringSlice(); len(reasons) != 1 || reasons[0] != "stop" {
+		t.Errorf("unexpected finish reasons %v", reasons)
+	}
+}
+
+func TestTracer_StreamAndErrors(t *testing.T) {
+	client, recorder := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var params cencori.ChatParams
+		json.NewDecoder(r.Body).Decode(&params)
+		if params.Model == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"bad model","code":"INVALID_MODEL"}`)
+			return
+		}
+		fmt.Fprint(w, "data: {\"id\":\"s-1\",\"model\":\"claude-3-sonnet\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":2,\"delta\":{},\"finish_reason\":\"length\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	chunks, err := client.Chat.Stream(context.Background(), &cencori.ChatParams{Model: "claude-3-sonnet"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for range chunks {
+	}
+
+	if _, err := client.Chat.Create(context.Background(), &cencori.ChatParams{Model: "bad"}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	stream := spans[0]
+	a := attrs(stream)
+	if a["gen_ai.system"].AsString() != "anthropic" || a["gen_ai.response.id"].AsString() != "s-1" {
+		t.Errorf("unexpected stream attributes: %v", a)
+	}
+	if _, ok := a["cencori.time_to_first_token"]; !ok {
+		t.Error("expected time to first token to be recorded")
+	}
+	if reasons := a["gen_ai.response.finish_reasons"].AsStringSlice(); len(reasons) != 2 || reasons[0] != "stop" || reasons[1] != "length" {
+		t.Errorf("unexpected finish reasons %v", reasons)
+	}
+	if len(stream.Events()) == 0 || stream.Events()[0].Name != "gen_ai.first_token" {
+		t.Errorf("expected a first token event, got %v", stream.Events())
+	}
+
+	failed := spans[1]
+	if failed.Status().Code != codes.Error || attrs(failed)["error.type"].AsString() != "INVALID_MODEL" {
+		t.Errorf("expected an error span, got status %v attrs %v", failed.Status(), attrs(failed))
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 // ProjectsService provides methods for managing project-related operations.
 // It uses a Client to communicate with the projects API endpoints.
+// Create, Update and Delete send an idempotency key so that retries are safe.
 type ProjectsService struct {
 	client *Client
 }
@@ -20,7 +21,8 @@ type ProjectsService struct {
 // Returns:
 //   - []Project: a slice of projects belonging to the organization
 //   - error: an error if the request fails
-func (s *ProjectsService) List(ctx context.Context, orgSlug string) ([]Project, error) {
+func (s *ProjectsService) List(ctx context.Context, orgSlug string, opts ...RequestOption) ([]Project, error) {
+	ctx = withRequestOptions(ctx, opts)
 	path := fmt.Sprintf("/api/organizations/%s/projects", orgSlug)
 
 	type response struct {
@@ -37,14 +39,17 @@ func (s *ProjectsService) List(ctx context.Context, orgSlug string) ([]Project,
 // Create creates a new project within the specified organization.
 // It sends a POST request to the organization's projects endpoint with the provided parameters.
 // Returns the created Project or an error if the request fails.
-func (s *ProjectsService) Create(ctx context.Context, orgSlug string, params CreateProjectParams) (*Project, error) {
+func (s *ProjectsService) Create(ctx context.Context, orgSlug string, params CreateProjectParams, opts ...RequestOption) (*Project, error) {
+	ctx = withRequestOptions(ctx, opts)
+	ctx = withIdempotencyKey(ctx)
 	path := fmt.Sprintf("/api/organizations/%s/projects", orgSlug)
 	return doRequest[CreateProjectParams, Project](s.client, ctx, "POST", path, &params)
 }
 
 // Get retrieves a project by its organization slug and project slug.
 // It returns the project details or an error if the request fails.
-func (s *ProjectsService) Get(ctx context.Context, orgSlug, projectSlug string) (*Project, error) {
+func (s *ProjectsService) Get(ctx context.Context, orgSlug, projectSlug string, opts ...RequestOption) (*Project, error) {
+	ctx = withRequestOptions(ctx, opts)
 	path := fmt.Sprintf("/api/organizations/%s/projects/%s", orgSlug, projectSlug)
 	return doRequest[any, Project](s.client, ctx, "GET", path, nil)
 }
@@ -52,7 +57,9 @@ func (s *ProjectsService) Get(ctx context.Context, orgSlug, projectSlug string)
 // Update updates a project in the specified organization.
 // It sends a PATCH request to the projects API endpoint with the given organization and project slugs.
 // Returns an error if the request fails.
-func (s *ProjectsService) Update(ctx context.Context, orgSlug, projectSlug string, params CreateProjectParams) error {
+func (s *ProjectsService) Update(ctx context.Context, orgSlug, projectSlug string, params CreateProjectParams, opts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, opts)
+	ctx = withIdempotencyKey(ctx)
 	path := fmt.Sprintf("/api/organizations/%s/projects/%s", orgSlug, projectSlug)
 	_, err := doRequest[CreateProjectParams, any](s.client, ctx, "PATCH", path, &params)
 	return err
@@ -61,7 +68,9 @@ func (s *ProjectsService) Update(ctx context.Context, orgSlug, projectSlug strin
 // Delete deletes a project in the specified organization.
 // It sends a DELETE request to the projects API endpoint with the given organization and project slugs.
 // Returns an error if the request fails.
-func (s *ProjectsService) Delete(ctx context.Context, orgSlug, projectSlug string) error {
+func (s *ProjectsService) Delete(ctx context.Context, orgSlug, projectSlug string, opts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, opts)
+	ctx = withIdempotencyKey(ctx)
 	path := fmt.Sprintf("/api/organizations/%s/projects/%s", orgSlug, projectSlug)
 	_, err := doRequest[any, any](s.client, ctx, "DELETE", path, nil)
 	return err
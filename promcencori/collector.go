@@ -0,0 +1,119 @@
+// Package promcencori exports cencori client-side metrics to Prometheus.
+//
+//	metrics := promcencori.New(prometheus.DefaultRegisterer)
+//	client, err := cencori.NewClient(
+//		cencori.WithAPIKey(key),
+//		cencori.WithMetrics(metrics),
+//	)
+package promcencori
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cencori/cencori-go"
+)
+
+// Options configures the Recorder.
+type Options struct {
+	// Namespace prefixes every metric name (default "cencori").
+	Namespace string
+	// LatencyBuckets are the histogram buckets in seconds for request
+	// latency and time to first token (default: 0.05s to 60s).
+	LatencyBuckets []float64
+}
+
+// Recorder implements cencori.MetricsRecorder with Prometheus metrics.
+type Recorder struct {
+	requests         *prometheus.CounterVec
+	latency          *prometheus.HistogramVec
+	timeToFirstToken *prometheus.HistogramVec
+	tokens           *prometheus.CounterVec
+	cost             *prometheus.CounterVec
+	retries          *prometheus.CounterVec
+	cacheHits        *prometheus.CounterVec
+}
+
+// New creates a Recorder with default options and registers its metrics with reg.
+func New(reg prometheus.Registerer) *Recorder {
+	return NewWithOptions(reg, Options{})
+}
+
+// NewWithOptions creates a Recorder and registers its metrics with reg.
+func NewWithOptions(reg prometheus.Registerer, opts Options) *Recorder {
+	if opts.Namespace == "" {
+		opts.Namespace = "cencori"
+	}
+	if len(opts.LatencyBuckets) == 0 {
+		opts.LatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	}
+
+	r := &Recorder{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "requests_total",
+			Help:      "Model calls by operation, model and HTTP status.",
+		}, []string{"operation", "model", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Model call latency; for streams, until the stream ends.",
+			Buckets:   opts.LatencyBuckets,
+		}, []string{"operation", "model"}),
+		timeToFirstToken: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "time_to_first_token_seconds",
+			Help:      "Time until the first streamed token arrived.",
+			Buckets:   opts.LatencyBuckets,
+		}, []string{"model"}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "tokens_total",
+			Help:      "Tokens used by model and type (prompt or completion).",
+		}, []string{"model", "type"}),
+		cost: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "cost_usd_total",
+			Help:      "Estimated cost in USD from the configured model prices.",
+		}, []string{"model"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "retries_total",
+			Help:      "Model call retries, including endpoint failover.",
+		}, []string{"operation", "model"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "cache_hits_total",
+			Help:      "Responses served from a client-side cache.",
+		}, []string{"operation", "model"}),
+	}
+	reg.MustRegister(r.requests, r.latency, r.timeToFirstToken, r.tokens, r.cost, r.retries, r.cacheHits)
+	return r
+}
+
+// ObserveRequest implements cencori.MetricsRecorder.
+func (r *Recorder) ObserveRequest(m cencori.CallMetrics) {
+	r.requests.WithLabelValues(m.Operation, m.Model, m.Status()).Inc()
+	r.latency.WithLabelValues(m.Operation, m.Model).Observe(m.Latency.Seconds())
+	if m.TimeToFirstToken > 0 {
+		r.timeToFirstToken.WithLabelValues(m.Model).Observe(m.TimeToFirstToken.Seconds())
+	}
+	if m.Usage.PromptTokens > 0 {
+		r.tokens.WithLabelValues(m.Model, "prompt").Add(float64(m.Usage.PromptTokens))
+	}
+	if m.Usage.CompletionTokens > 0 {
+		r.tokens.WithLabelValues(m.Model, "completion").Add(float64(m.Usage.CompletionTokens))
+	}
+	if m.Cost > 0 {
+		r.cost.WithLabelValues(m.Model).Add(m.Cost)
+	}
+}
+
+// IncRetry implements cencori.MetricsRecorder.
+func (r *Recorder) IncRetry(operation, model string) {
+	r.retries.WithLabelValues(operation, model).Inc()
+}
+
+// IncCacheHit implements cencori.MetricsRecorder.
+func (r *Recorder) IncCacheHit(operation, model string) {
+	r.cacheHits.WithLabelValues(operation, model).Inc()
+}
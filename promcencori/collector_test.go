@@ -0,0 +1,73 @@
+package promcencori
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/cencori/cencori-go"
+)
+
+func TestRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params cencori.ChatParams
+		json.NewDecoder(r.Body).Decode(&params)
+		if params.Model == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"bad model","code":"INVALID_MODEL"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(cencori.ChatResponse{Usage: cencori.Usage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500}})
+	}))
+	defer server.Close()
+
+	reg := prometheus.NewPedanticRegistry()
+	recorder := New(reg)
+	client, _ := cencori.NewClient(
+		cencori.WithAPIKey("test-key"),
+		cencori.WithBaseURL(server.URL),
+		cencori.WithMetrics(recorder),
+		cencori.WithCache(cencori.CacheConfig{CacheNonDeterministic: true}),
+		cencori.WithPricing(map[string]cencori.Price{"gpt-4o": {PromptPerMillion: 2, CompletionPerMillion: 10}}),
+	)
+
+	params := func(model string) *cencori.ChatParams {
+		return &cencori.ChatParams{Model: model, Messages: []cencori.Message{{Role: "user", Content: "Hi"}}}
+	}
+	for range 2 {
+		if _, err := client.Chat.Create(context.Background(), params("gpt-4o")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	client.Chat.Create(context.Background(), params("bad"))
+
+	expected := `
+# HELP cencori_requests_total Model calls by operation, model and HTTP status.
+# TYPE cencori_requests_total counter
+cencori_requests_total{model="bad",operation="chat",status="400"} 1
+cencori_requests_total{model="gpt-4o",operation="chat",status="200"} 1
+# HELP cencori_tokens_total Tokens used by model and type (prompt or completion).
+# TYPE cencori_tokens_total counter
+cencori_tokens_total{model="gpt-4o",type="completion"} 500
+cencori_tokens_total{model="gpt-4o",type="prompt"} 1000
+# HELP cencori_cost_usd_total Estimated cost in USD from the configured model prices.
+# TYPE cencori_cost_usd_total counter
+cencori_cost_usd_total{model="gpt-4o"} 0.007
+# HELP cencori_cache_hits_total Responses served from a client-side cache.
+# TYPE cencori_cache_hits_total counter
+cencori_cache_hits_total{model="gpt-4o",operation="chat"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"cencori_requests_total", "cencori_tokens_total", "cencori_cost_usd_total", "cencori_cache_hits_total"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(recorder.latency); n != 2 {
+		t.Errorf("expected latency series for 2 models, got %d", n)
+	}
+}
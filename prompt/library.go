@@ -0,0 +1,146 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned by Library when a prompt or version does not exist.
+var ErrNotFound = errors.New("prompt: not found")
+
+// Library is a set of versioned prompts loaded from a file system.
+type Library struct {
+	prompts map[string][]*Template // sorted by version, oldest first
+}
+
+// Load reads the prompts under dir in fsys. Each prompt lives in its own
+// directory with one file per version, and partials shared by all prompts
+// live in dir/partials:
+//
+//	prompts/
+//	  partials/tone.tmpl        {{template "tone" .}}
+//	  summarize/v1.prompt
+//	  summarize/v2.prompt
+//	  support/triage/v1.prompt  named "support/triage"
+//
+// A partial is available to every prompt under its file name without the
+// extension.
+func Load(fsys fs.FS, dir string) (*Library, error) {
+	base := newBase()
+	partials := path.Join(dir, "partials")
+	if _, err := fs.Stat(fsys, partials); err == nil {
+		err := fs.WalkDir(fsys, partials, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || path.Ext(p) != ".tmpl" {
+				return err
+			}
+			data, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSuffix(strings.TrimPrefix(p, partials+"/"), ".tmpl")
+			if _, err := base.New(name).Parse(string(data)); err != nil {
+				return fmt.Errorf("partial %s: %w", name, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("load partials: %w", err)
+		}
+	}
+
+	lib := &Library{prompts: make(map[string][]*Template)}
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p == partials {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path.Ext(p) != ".prompt" {
+			return nil
+		}
+
+		name, version, ok := promptPath(dir, p)
+		if !ok {
+			return fmt.Errorf("%s: prompt files must be named <name>/v<version>.prompt", p)
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		tmplBase, err := base.Clone()
+		if err != nil {
+			return err
+		}
+		t, err := parse(tmplBase, name, version, string(data))
+		if err != nil {
+			return err
+		}
+		lib.prompts[name] = append(lib.prompts[name], t)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load prompts: %w", err)
+	}
+
+	for _, versions := range lib.prompts {
+		slices.SortFunc(versions, func(a, b *Template) int { return a.Version - b.Version })
+	}
+	return lib, nil
+}
+
+// promptPath splits dir/<name>/v<version>.prompt into name and version.
+func promptPath(dir, p string) (string, int, bool) {
+	rel := strings.TrimPrefix(p, dir+"/")
+	if dir == "." {
+		rel = p
+	}
+	name, file := path.Split(rel)
+	name = strings.TrimSuffix(name, "/")
+	digits, ok := strings.CutPrefix(strings.TrimSuffix(file, ".prompt"), "v")
+	if name == "" || !ok {
+		return "", 0, false
+	}
+	version, err := strconv.Atoi(digits)
+	if err != nil || version < 0 {
+		return "", 0, false
+	}
+	return name, version, true
+}
+
+// Names returns the names of all prompts in the library, sorted.
+func (l *Library) Names() []string {
+	names := make([]string, 0, len(l.prompts))
+	for name := range l.prompts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Get returns the latest version of the named prompt.
+func (l *Library) Get(name string) (*Template, error) {
+	versions := l.prompts[name]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return versions[len(versions)-1], nil
+}
+
+// Version returns a specific version of the named prompt.
+func (l *Library) Version(name string, version int) (*Template, error) {
+	for _, t := range l.prompts[name] {
+		if t.Version == version {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s v%d", ErrNotFound, name, version)
+}
@@ -0,0 +1,380 @@
+// Package prompt renders chat messages from text/template prompt files.
+//
+// A prompt file is split into sections by header lines of the form
+// "-- name --":
+//
+//	-- vars --
+//	text string
+//	max_words int
+//	tone string optional
+//	-- system --
+//	You are a concise assistant. {{template "tone" .}}
+//	-- example.user --
+//	Summarize: The meeting moved to Friday.
+//	-- example.assistant --
+//	Meeting is now Friday.
+//	-- examples --
+//	-- user --
+//	Summarize in at most {{.max_words}} words:
+//	{{.text}}
+//
+// The system, user and assistant sections become messages in file order.
+// The vars section declares one variable per line as "name kind", optionally
+// followed by "optional"; Render rejects missing, unknown or mistyped
+// variables before anything is sent. example.user and example.assistant
+// pairs are the default few-shot examples, inserted at the examples marker
+// (or after the leading system messages when there is none).
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/cencori/cencori-go"
+)
+
+// Kind is the type of a template variable.
+type Kind string
+
+const (
+	String Kind = "string"
+	Int    Kind = "int"
+	Float  Kind = "float"
+	Bool   Kind = "bool"
+	List   Kind = "list"
+	Any    Kind = "any"
+)
+
+// accepts reports whether v is a valid value for a variable of kind k.
+func (k Kind) accepts(v any) bool {
+	if k == Any {
+		return true
+	}
+	if v == nil {
+		return k == List
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.String:
+		return k == String
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return k == Int || k == Float
+	case reflect.Float32, reflect.Float64:
+		return k == Float
+	case reflect.Bool:
+		return k == Bool
+	case reflect.Slice, reflect.Array:
+		return k == List
+	default:
+		return false
+	}
+}
+
+// zero is the value an omitted optional variable renders as.
+func (k Kind) zero() any {
+	switch k {
+	case String:
+		return ""
+	case Int:
+		return 0
+	case Float:
+		return 0.0
+	case Bool:
+		return false
+	case List:
+		return []any{}
+	default:
+		return nil
+	}
+}
+
+// Var declares a template variable.
+type Var struct {
+	Name     string
+	Kind     Kind
+	Optional bool
+}
+
+// Vars are the values a template is rendered with.
+type Vars map[string]any
+
+// Example is one few-shot exchange.
+type Example struct {
+	User      string
+	Assistant string
+}
+
+// MissingVarsError is returned when required variables were not supplied.
+type MissingVarsError struct {
+	Template string
+	Names    []string
+}
+
+func (e *MissingVarsError) Error() string {
+	return fmt.Sprintf("prompt %s: missing variables: %s", e.Template, strings.Join(e.Names, ", "))
+}
+
+// Template is a parsed prompt. It is safe for concurrent use.
+type Template struct {
+	Name    string
+	Version int
+	// Vars are the declared variables, or nil if the template has no vars
+	// section, in which case any variables are accepted.
+	Vars []Var
+
+	tmpl     *template.Template
+	sections []section
+}
+
+type section struct {
+	role string // system, user, assistant, example.user, example.assistant or examples
+	name string // associated template name, empty for the examples marker
+}
+
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+func newBase() *template.Template {
+	return template.New("").Funcs(funcs).Option("missingkey=error")
+}
+
+// Parse parses a prompt file without partials.
+func Parse(name, text string) (*Template, error) {
+	return parse(newBase(), name, 0, text)
+}
+
+func parse(base *template.Template, name string, version int, text string) (*Template, error) {
+	t := &Template{Name: name, Version: version, tmpl: base}
+
+	var role string
+	var body strings.Builder
+	flush := func() error {
+		if role == "" {
+			if strings.TrimSpace(body.String()) != "" {
+				return fmt.Errorf("prompt %s: text before the first section", name)
+			}
+			return nil
+		}
+		return t.addSection(role, body.String())
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if header, ok := sectionHeader(line); ok {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			role = header
+			body.Reset()
+			continue
+		}
+		body.WriteString(line)
+		body.WriteByte('\n')
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if err := t.checkExamples(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func sectionHeader(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "-- ") || !strings.HasSuffix(line, " --") || len(line) < 7 {
+		return "", false
+	}
+	return strings.TrimSpace(line[3 : len(line)-3]), true
+}
+
+func (t *Template) addSection(role, body string) error {
+	switch role {
+	case "vars":
+		if t.Vars != nil {
+			return fmt.Errorf("prompt %s: duplicate vars section", t.Name)
+		}
+		vars, err := parseVars(body)
+		if err != nil {
+			return fmt.Errorf("prompt %s: %w", t.Name, err)
+		}
+		t.Vars = vars
+	case "examples":
+		if slices.ContainsFunc(t.sections, func(s section) bool { return s.role == "examples" }) {
+			return fmt.Errorf("prompt %s: duplicate examples section", t.Name)
+		}
+		t.sections = append(t.sections, section{role: role})
+	case "system", "user", "assistant", "example.user", "example.assistant":
+		name := fmt.Sprintf("%s#%d", t.Name, len(t.sections))
+		if _, err := t.tmpl.New(name).Parse(strings.TrimSpace(body)); err != nil {
+			return fmt.Errorf("prompt %s: %s section: %w", t.Name, role, err)
+		}
+		t.sections = append(t.sections, section{role: role, name: name})
+	default:
+		return fmt.Errorf("prompt %s: unknown section %q", t.Name, role)
+	}
+	return nil
+}
+
+func parseVars(body string) ([]Var, error) {
+	vars := []Var{}
+	for line := range strings.Lines(body) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 3 || (len(fields) == 3 && fields[2] != "optional") {
+			return nil, fmt.Errorf("invalid variable declaration %q", strings.TrimSpace(line))
+		}
+		v := Var{Name: fields[0], Kind: Any}
+		if len(fields) > 1 {
+			v.Kind = Kind(fields[1])
+		}
+		switch v.Kind {
+		case String, Int, Float, Bool, List, Any:
+		default:
+			return nil, fmt.Errorf("variable %s: unknown kind %q", v.Name, v.Kind)
+		}
+		v.Optional = len(fields) == 3
+		if slices.ContainsFunc(vars, func(o Var) bool { return o.Name == v.Name }) {
+			return nil, fmt.Errorf("variable %s declared twice", v.Name)
+		}
+		vars = append(vars, v)
+	}
+	return vars, nil
+}
+
+// checkExamples ensures in-file examples come in user/assistant pairs.
+func (t *Template) checkExamples() error {
+	want := "example.user"
+	for _, s := range t.sections {
+		if !strings.HasPrefix(s.role, "example.") {
+			continue
+		}
+		if s.role != want {
+			return fmt.Errorf("prompt %s: %s section out of order", t.Name, s.role)
+		}
+		if want == "example.user" {
+			want = "example.assistant"
+		} else {
+			want = "example.user"
+		}
+	}
+	if want != "example.user" {
+		return fmt.Errorf("prompt %s: example.user without example.assistant", t.Name)
+	}
+	return nil
+}
+
+// Validate checks vars against the declared variables: every required
+// variable must be present, every value must match its kind, and no
+// undeclared variables may be passed.
+func (t *Template) Validate(vars Vars) error {
+	if t.Vars == nil {
+		return nil
+	}
+
+	var missing []string
+	for _, v := range t.Vars {
+		val, ok := vars[v.Name]
+		if !ok {
+			if !v.Optional {
+				missing = append(missing, v.Name)
+			}
+			continue
+		}
+		if !v.Kind.accepts(val) {
+			return fmt.Errorf("prompt %s: variable %s must be %s, got %T", t.Name, v.Name, v.Kind, val)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingVarsError{Template: t.Name, Names: missing}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		if !slices.ContainsFunc(t.Vars, func(v Var) bool { return v.Name == name }) {
+			return fmt.Errorf("prompt %s: unknown variable %s", t.Name, name)
+		}
+	}
+	return nil
+}
+
+// Render validates vars and renders the prompt's messages. Examples passed
+// here replace the examples defined in the file.
+func (t *Template) Render(vars Vars, examples ...Example) ([]cencori.Message, error) {
+	if err := t.Validate(vars); err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]any, len(vars))
+	for _, v := range t.Vars {
+		if v.Optional {
+			data[v.Name] = v.Kind.zero()
+		}
+	}
+	for k, v := range vars {
+		data[k] = v
+	}
+
+	var messages, fileExamples []cencori.Message
+	marker := -1
+	for _, s := range t.sections {
+		if s.role == "examples" {
+			marker = len(messages)
+			continue
+		}
+
+		var b strings.Builder
+		if err := t.tmpl.ExecuteTemplate(&b, s.name, data); err != nil {
+			return nil, fmt.Errorf("prompt %s: %w", t.Name, err)
+		}
+		msg := cencori.Message{Role: strings.TrimPrefix(s.role, "example."), Content: strings.TrimSpace(b.String())}
+		if strings.HasPrefix(s.role, "example.") {
+			fileExamples = append(fileExamples, msg)
+		} else {
+			messages = append(messages, msg)
+		}
+	}
+
+	shots := fileExamples
+	if len(examples) > 0 {
+		shots = make([]cencori.Message, 0, 2*len(examples))
+		for _, e := range examples {
+			shots = append(shots,
+				cencori.Message{Role: "user", Content: e.User},
+				cencori.Message{Role: "assistant", Content: e.Assistant},
+			)
+		}
+	}
+	if len(shots) == 0 {
+		return messages, nil
+	}
+
+	if marker < 0 {
+		marker = 0
+		for marker < len(messages) && messages[marker].Role == "system" {
+			marker++
+		}
+	}
+	return slices.Insert(messages, marker, shots...), nil
+}
+
+// Params renders the prompt into chat parameters for model.
+func (t *Template) Params(model string, vars Vars, examples ...Example) (*cencori.ChatParams, error) {
+	messages, err := t.Render(vars, examples...)
+	if err != nil {
+		return nil, err
+	}
+	return &cencori.ChatParams{Model: model, Messages: messages}, nil
+}
@@ -0,0 +1,144 @@
+package prompt
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/cencori/cencori-go"
+)
+
+const summarize = `-- vars --
+text string
+max_words int
+style string optional
+-- system --
+You are a concise assistant.{{if .style}} Write in a {{.style}} style.{{end}}
+-- example.user --
+Summarize: The meeting moved to Friday.
+-- example.assistant --
+Meeting is now Friday.
+-- user --
+Summarize in at most {{.max_words}} words:
+{{.text}}
+`
+
+func TestRender(t *testing.T) {
+	tmpl, err := Parse("summarize", summarize)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	messages, err := tmpl.Render(Vars{"text": "Long text.", "max_words": 10})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want := []cencori.Message{
+		{Role: "system", Content: "You are a concise assistant."},
+		{Role: "user", Content: "Summarize: The meeting moved to Friday."},
+		{Role: "assistant", Content: "Meeting is now Friday."},
+		{Role: "user", Content: "Summarize in at most 10 words:\nLong text."},
+	}
+	assertMessages(t, messages, want)
+
+	messages, err = tmpl.Render(Vars{"text": "x", "max_words": 5, "style": "formal"}, Example{User: "q", Assistant: "a"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if messages[0].Content != "You are a concise assistant. Write in a formal style." {
+		t.Errorf("optional variable not rendered: %q", messages[0].Content)
+	}
+	if messages[1].Content != "q" || messages[2].Content != "a" || len(messages) != 4 {
+		t.Errorf("expected passed examples to replace file examples, got %+v", messages)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tmpl, err := Parse("summarize", summarize)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var missing *MissingVarsError
+	if _, err := tmpl.Render(Vars{"text": "x"}); !errors.As(err, &missing) || missing.Names[0] != "max_words" {
+		t.Errorf("expected MissingVarsError for max_words, got %v", err)
+	}
+	if _, err := tmpl.Render(Vars{"text": "x", "max_words": "ten"}); err == nil {
+		t.Error("expected a kind mismatch error")
+	}
+	if _, err := tmpl.Render(Vars{"text": "x", "max_words": 1, "txet": "typo"}); err == nil {
+		t.Error("expected an unknown variable error")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, text := range map[string]string{
+		"text before section": "hello\n-- user --\nhi",
+		"unknown section":     "-- narrator --\nhi",
+		"unknown kind":        "-- vars --\nx number\n-- user --\n{{.x}}",
+		"unpaired example":    "-- example.user --\nq\n-- user --\nhi",
+		"bad template":        "-- user --\n{{.x",
+	} {
+		if _, err := Parse(name, text); err == nil {
+			t.Errorf("%s: expected a parse error", name)
+		}
+	}
+}
+
+func TestLibrary(t *testing.T) {
+	fsys := fstest.MapFS{
+		"prompts/partials/sign.tmpl":        {Data: []byte("-- {{.team}}")},
+		"prompts/greet/v1.prompt":           {Data: []byte("-- user --\nHello")},
+		"prompts/greet/v2.prompt":           {Data: []byte("-- vars --\nteam string\n-- examples --\n-- user --\nHi {{template \"sign\" .}}")},
+		"prompts/support/triage/v10.prompt": {Data: []byte("-- user --\nTriage")},
+	}
+	lib, err := Load(fsys, "prompts")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if names := lib.Names(); len(names) != 2 || names[0] != "greet" || names[1] != "support/triage" {
+		t.Errorf("unexpected names: %v", names)
+	}
+
+	latest, err := lib.Get("greet")
+	if err != nil || latest.Version != 2 {
+		t.Fatalf("expected greet v2, got %+v, %v", latest, err)
+	}
+	params, err := latest.Params("gpt-4o", Vars{"team": "support"}, Example{User: "q", Assistant: "a"})
+	if err != nil {
+		t.Fatalf("Params failed: %v", err)
+	}
+	assertMessages(t, params.Messages, []cencori.Message{
+		{Role: "user", Content: "q"},
+		{Role: "assistant", Content: "a"},
+		{Role: "user", Content: "Hi -- support"},
+	})
+
+	if v1, err := lib.Version("greet", 1); err != nil || v1.Version != 1 {
+		t.Errorf("expected greet v1, got %+v, %v", v1, err)
+	}
+	if _, err := lib.Version("greet", 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := lib.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	fsys["prompts/greet/latest.prompt"] = &fstest.MapFile{Data: []byte("-- user --\nHi")}
+	if _, err := Load(fsys, "prompts"); err == nil {
+		t.Error("expected an error for a badly named prompt file")
+	}
+}
+
+func assertMessages(t *testing.T, got, want []cencori.Message) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d messages, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("message %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
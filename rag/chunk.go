@@ -0,0 +1,298 @@
+package rag
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Format tells the Chunker how a document is structured.
+type Format int
+
+const (
+	// FormatAuto detects the format from the document ID's extension and content.
+	FormatAuto Format = iota
+	// FormatText splits on blank lines.
+	FormatText
+	// FormatMarkdown splits on headings and paragraphs and keeps fenced code blocks whole.
+	FormatMarkdown
+	// FormatCode splits between top-level declarations.
+	FormatCode
+)
+
+// Document is a source text to be chunked and indexed.
+type Document struct {
+	ID       string
+	Text     string
+	Format   Format
+	Metadata map[string]string
+}
+
+// Chunk is a piece of a Document small enough to embed and to fit in a prompt.
+type Chunk struct {
+	ID         string
+	DocumentID string
+	Index      int
+	Text       string
+	// Heading is the closest Markdown heading above the chunk, if any.
+	Heading  string
+	Metadata map[string]string
+}
+
+// Chunker splits documents into overlapping chunks of roughly ChunkTokens tokens.
+type Chunker struct {
+	// ChunkTokens is the target chunk size (default 512).
+	ChunkTokens int
+	// OverlapTokens is how much of the previous chunk is repeated at the start
+	// of the next one (default 64).
+	OverlapTokens int
+	// TokenCounter estimates tokens (default: one token per four bytes).
+	TokenCounter func(string) int
+}
+
+func (c *Chunker) setDefaults() {
+	if c.ChunkTokens <= 0 {
+		c.ChunkTokens = 512
+	}
+	if c.OverlapTokens < 0 || c.OverlapTokens >= c.ChunkTokens {
+		c.OverlapTokens = 0
+	} else if c.OverlapTokens == 0 {
+		c.OverlapTokens = min(64, c.ChunkTokens/4)
+	}
+	if c.TokenCounter == nil {
+		c.TokenCounter = estimateTokens
+	}
+}
+
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+type unit struct {
+	text    string
+	heading string
+	tokens  int
+}
+
+// Split chunks doc. Chunk IDs are "<document ID>#<index>".
+func (c Chunker) Split(doc Document) []Chunk {
+	c.setDefaults()
+
+	var units []unit
+	switch detectFormat(doc) {
+	case FormatMarkdown:
+		units = markdownUnits(doc.Text)
+	case FormatCode:
+		units = codeUnits(doc.Text)
+	case FormatText, FormatAuto:
+		units = textUnits(doc.Text)
+	}
+
+	var chunks []Chunk
+	emit := func(text, heading string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		chunks = append(chunks, Chunk{
+			ID:         fmt.Sprintf("%s#%d", doc.ID, len(chunks)),
+			DocumentID: doc.ID,
+			Index:      len(chunks),
+			Text:       text,
+			Heading:    heading,
+			Metadata:   doc.Metadata,
+		})
+	}
+
+	for i := range units {
+		units[i].tokens = c.TokenCounter(units[i].text)
+	}
+
+	var pending []unit
+	for _, u := range units {
+		if u.tokens > c.ChunkTokens {
+			// Too big on its own: flush what we have, then split it by lines and words.
+			c.pack(pending, "\n\n", emit)
+			pending = nil
+			c.pack(c.fragments(u), "", emit)
+			continue
+		}
+		pending = append(pending, u)
+	}
+	c.pack(pending, "\n\n", emit)
+	return chunks
+}
+
+// pack greedily groups units into chunks of at most ChunkTokens, starting
+// each new chunk with trailing units of the previous one up to OverlapTokens.
+func (c Chunker) pack(units []unit, sep string, emit func(text, heading string)) {
+	var cur []unit
+	tokens := 0
+	flush := func() {
+		if len(cur) == 0 {
+			return
+		}
+		parts := make([]string, len(cur))
+		for i, u := range cur {
+			parts[i] = u.text
+		}
+		emit(strings.Join(parts, sep), cur[len(cur)-1].heading)
+	}
+
+	for _, u := range units {
+		if len(cur) > 0 && tokens+u.tokens > c.ChunkTokens {
+			flush()
+
+			// Keep a tail of the chunk as overlap.
+			start := len(cur)
+			overlap := 0
+			for start > 0 && overlap+cur[start-1].tokens <= c.OverlapTokens {
+				start--
+				overlap += cur[start].tokens
+			}
+			cur = append([]unit(nil), cur[start:]...)
+			tokens = overlap
+			for len(cur) > 0 && tokens+u.tokens > c.ChunkTokens {
+				tokens -= cur[0].tokens
+				cur = cur[1:]
+			}
+		}
+		cur = append(cur, u)
+		tokens += u.tokens
+	}
+	flush()
+}
+
+// fragments breaks an oversized unit into lines, and lines into words, so that
+// every fragment fits in a chunk. Fragments keep their separators.
+func (c Chunker) fragments(u unit) []unit {
+	var out []unit
+	for _, line := range strings.SplitAfter(u.text, "\n") {
+		if n := c.TokenCounter(line); n <= c.ChunkTokens {
+			out = append(out, unit{text: line, heading: u.heading, tokens: n})
+			continue
+		}
+		for _, word := range strings.SplitAfter(line, " ") {
+			out = append(out, unit{text: word, heading: u.heading, tokens: c.TokenCounter(word)})
+		}
+	}
+	return out
+}
+
+var codeExtensions = map[string]bool{
+	".go": true, ".py": true, ".js": true, ".ts": true, ".tsx": true, ".jsx": true,
+	".java": true, ".kt": true, ".rs": true, ".c": true, ".h": true, ".cpp": true,
+	".cs": true, ".rb": true, ".php": true, ".swift": true, ".scala": true, ".sh": true,
+}
+
+func detectFormat(doc Document) Format {
+	if doc.Format != FormatAuto {
+		return doc.Format
+	}
+	ext := strings.ToLower(path.Ext(doc.ID))
+	switch {
+	case ext == ".md" || ext == ".markdown":
+		return FormatMarkdown
+	case codeExtensions[ext]:
+		return FormatCode
+	case strings.HasPrefix(doc.Text, "# ") || strings.Contains(doc.Text, "\n# ") ||
+		strings.Contains(doc.Text, "\n## ") || strings.Contains(doc.Text, "```"):
+		return FormatMarkdown
+	default:
+		return FormatText
+	}
+}
+
+func textUnits(text string) []unit {
+	var units []unit
+	for _, p := range strings.Split(text, "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			units = append(units, unit{text: p})
+		}
+	}
+	return units
+}
+
+// markdownUnits splits on headings and blank lines, never inside a fenced
+// code block, and records the current heading for each unit.
+func markdownUnits(text string) []unit {
+	var (
+		units   []unit
+		buf     []string
+		heading string
+		inFence bool
+	)
+	flush := func() {
+		if t := strings.TrimSpace(strings.Join(buf, "\n")); t != "" {
+			units = append(units, unit{text: t, heading: heading})
+		}
+		buf = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			if !inFence {
+				flush()
+			}
+			buf = append(buf, line)
+			inFence = !inFence
+			if !inFence {
+				flush()
+			}
+		case inFence:
+			buf = append(buf, line)
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			heading = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			buf = append(buf, line)
+		case trimmed == "":
+			flush()
+		default:
+			buf = append(buf, line)
+		}
+	}
+	flush()
+
+	// Attach each heading to the paragraph that follows it.
+	merged := units[:0]
+	for i := 0; i < len(units); i++ {
+		u := units[i]
+		if strings.HasPrefix(u.text, "#") && !strings.Contains(u.text, "\n") && i+1 < len(units) {
+			units[i+1].text = u.text + "\n\n" + units[i+1].text
+			continue
+		}
+		merged = append(merged, u)
+	}
+	return merged
+}
+
+// codeUnits splits source code at blank lines that are followed by a line
+// starting in column zero, which approximates top-level declaration boundaries.
+func codeUnits(text string) []unit {
+	lines := strings.Split(text, "\n")
+	var (
+		units []unit
+		buf   []string
+	)
+	flush := func() {
+		if t := strings.Trim(strings.Join(buf, "\n"), "\n"); strings.TrimSpace(t) != "" {
+			units = append(units, unit{text: t})
+		}
+		buf = nil
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" && i+1 < len(lines) {
+			next := lines[i+1]
+			if next != "" && next[0] != ' ' && next[0] != '\t' && next[0] != '}' && next[0] != ')' {
+				flush()
+				continue
+			}
+		}
+		buf = append(buf, line)
+	}
+	flush()
+	return units
+}
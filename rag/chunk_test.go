@@ -0,0 +1,96 @@
+package rag
+
+import (
+	"strings"
+	"testing"
+)
+
+// wordCounter counts whitespace-separated words, which keeps test sizes readable.
+func wordCounter(s string) int {
+	return len(strings.Fields(s))
+}
+
+func TestChunker_TextOverlap(t *testing.T) {
+	doc := Document{
+		ID:   "notes.txt",
+		Text: "one two three\n\nfour five six\n\nseven eight nine\n\nten eleven twelve",
+	}
+	chunks := Chunker{ChunkTokens: 6, OverlapTokens: 3, TokenCounter: wordCounter}.Split(doc)
+
+	want := []string{
+		"one two three\n\nfour five six",
+		"four five six\n\nseven eight nine",
+		"seven eight nine\n\nten eleven twelve",
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d: %+v", len(chunks), len(want), chunks)
+	}
+	for i, c := range chunks {
+		if c.Text != want[i] {
+			t.Errorf("chunk %d = %q, want %q", i, c.Text, want[i])
+		}
+		if c.ID != "notes.txt#"+string(rune('0'+i)) || c.DocumentID != "notes.txt" || c.Index != i {
+			t.Errorf("chunk %d has wrong identity: %+v", i, c)
+		}
+	}
+}
+
+func TestChunker_MarkdownKeepsFencesAndHeadings(t *testing.T) {
+	doc := Document{
+		ID: "guide.md",
+		Text: "# Install\n\nRun the installer.\n\n" +
+			"## Usage\n\nCall the client.\n\n```go\nclient := New()\n\nclient.Do()\n```\n\nDone.",
+	}
+	chunks := Chunker{ChunkTokens: 8, OverlapTokens: -1, TokenCounter: wordCounter}.Split(doc)
+
+	var fence *Chunk
+	for i := range chunks {
+		if strings.Contains(chunks[i].Text, "```go") {
+			fence = &chunks[i]
+		}
+	}
+	if fence == nil {
+		t.Fatalf("no chunk contains the code fence: %+v", chunks)
+	}
+	if !strings.Contains(fence.Text, "client := New()\n\nclient.Do()\n```") {
+		t.Errorf("code fence was split: %q", fence.Text)
+	}
+	if fence.Heading != "Usage" {
+		t.Errorf("expected heading Usage, got %q", fence.Heading)
+	}
+	if chunks[0].Heading != "Install" || !strings.HasPrefix(chunks[0].Text, "# Install\n\nRun the installer.") {
+		t.Errorf("heading not attached to its paragraph: %+v", chunks[0])
+	}
+}
+
+func TestChunker_CodeSplitsAtDeclarations(t *testing.T) {
+	doc := Document{
+		ID:   "main.go",
+		Text: "package main\n\nfunc a() {\n\tx := 1\n\n\treturn\n}\n\nfunc b() {\n\treturn\n}\n",
+	}
+	chunks := Chunker{ChunkTokens: 10, OverlapTokens: -1, TokenCounter: wordCounter}.Split(doc)
+
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2: %+v", len(chunks), chunks)
+	}
+	if !strings.Contains(chunks[0].Text, "func a() {\n\tx := 1\n\n\treturn\n}") {
+		t.Errorf("func a was split at an inner blank line: %q", chunks[0].Text)
+	}
+	if !strings.HasPrefix(chunks[1].Text, "func b()") {
+		t.Errorf("expected func b in its own chunk, got %q", chunks[1].Text)
+	}
+}
+
+func TestChunker_OversizedUnit(t *testing.T) {
+	doc := Document{ID: "long.txt", Text: strings.Repeat("word ", 25)}
+	chunks := Chunker{ChunkTokens: 10, OverlapTokens: 2, TokenCounter: wordCounter}.Split(doc)
+
+	if len(chunks) < 3 {
+		t.Fatalf("expected the paragraph to be split, got %d chunks", len(chunks))
+	}
+	for _, c := range chunks {
+		if n := wordCounter(c.Text); n > 10 {
+			t.Errorf("chunk %s has %d tokens, want <= 10", c.ID, n)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Package rag implements retrieval-augmented generation on top of
+// cencori's ChatService: chunk documents, embed and index them, retrieve the
+// chunks relevant to a question, and ask a model to answer with citations
+// that map back to the chunks they came from.
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cencori/cencori-go"
+)
+
+// Generator produces chat completions. *cencori.ChatService satisfies it.
+type Generator interface {
+	Create(ctx context.Context, params *cencori.ChatParams, opts ...cencori.RequestOption) (*cencori.ChatResponse, error)
+}
+
+var _ Generator = (*cencori.ChatService)(nil)
+
+// Pipeline answers questions from retrieved context.
+type Pipeline struct {
+	Generator Generator
+	Retriever Retriever
+	// Model is the chat model used to answer.
+	Model string
+	// TopK is the number of chunks retrieved per question (default 5).
+	TopK int
+	// Prompt builds the messages sent to the model.
+	Prompt PromptBuilder
+	// Temperature is passed through to the chat request when set.
+	Temperature *float64
+	// MaxTokens is passed through to the chat request when set.
+	MaxTokens *int
+}
+
+// Citation maps a span of the answer to the chunks it cites.
+type Citation struct {
+	// Start and End are byte offsets of the cited span in Answer.Text.
+	Start, End int
+	// Sources are the 1-based source numbers cited for the span.
+	Sources []int
+	// ChunkIDs are the IDs of the cited chunks.
+	ChunkIDs []string
+}
+
+// Answer is the result of Pipeline.Ask.
+type Answer struct {
+	Text string
+	// Sources are the chunks given to the model; source n is Sources[n-1].
+	Sources   []Retrieved
+	Citations []Citation
+	Response  *cencori.ChatResponse
+}
+
+// Ask retrieves context for question and asks the model to answer it.
+func (p *Pipeline) Ask(ctx context.Context, question string) (*Answer, error) {
+	k := p.TopK
+	if k <= 0 {
+		k = 5
+	}
+
+	retrieved, err := p.Retriever.Retrieve(ctx, question, k)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, sources := p.Prompt.Build(question, retrieved)
+	resp, err := p.Generator.Create(ctx, &cencori.ChatParams{
+		Model:       p.Model,
+		Messages:    messages,
+		Temperature: p.Temperature,
+		MaxTokens:   p.MaxTokens,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rag: generate: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("rag: generate: no choices returned")
+	}
+
+	text := resp.Choices[0].Message.Content
+	return &Answer{
+		Text:      text,
+		Sources:   sources,
+		Citations: ParseCitations(text, sources),
+		Response:  resp,
+	}, nil
+}
+
+var (
+	citationRun    = regexp.MustCompile(`(?:\s*\[\d+(?:\s*,\s*\d+)*\])+`)
+	citationNumber = regexp.MustCompile(`\d+`)
+)
+
+// ParseCitations finds bracketed source numbers such as [1] or [2, 3] in
+// text and maps the span of text they follow, back to the previous citation
+// or sentence boundary, to the cited chunks. Numbers outside sources are ignored.
+func ParseCitations(text string, sources []Retrieved) []Citation {
+	var out []Citation
+	spanStart := 0
+	for _, loc := range citationRun.FindAllStringIndex(text, -1) {
+		start := spanStart
+		if b := lastSentenceBoundary(text[spanStart:loc[0]]); b >= 0 {
+			start = spanStart + b
+		}
+		start += len(text[start:loc[0]]) - len(strings.TrimLeft(text[start:loc[0]], " \t\n"))
+
+		var c Citation
+		for _, num := range citationNumber.FindAllString(text[loc[0]:loc[1]], -1) {
+			n, err := strconv.Atoi(num)
+			if err != nil || n < 1 || n > len(sources) {
+				continue
+			}
+			c.Sources = append(c.Sources, n)
+			c.ChunkIDs = append(c.ChunkIDs, sources[n-1].ID)
+		}
+		spanStart = loc[1]
+		if len(c.Sources) == 0 || start >= loc[0] {
+			continue
+		}
+		c.Start, c.End = start, loc[0]
+		out = append(out, c)
+	}
+	return out
+}
+
+// lastSentenceBoundary returns the offset just after the last sentence end
+// in s that is followed by more text, or -1 if there is none.
+func lastSentenceBoundary(s string) int {
+	trimmed := strings.TrimRight(s, " \t\n")
+	for i := len(trimmed) - 1; i >= 0; i-- {
+		switch trimmed[i] {
+		case '.', '!', '?', '\n':
+			if i < len(trimmed)-1 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,122 @@
+package rag
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cencori/cencori-go"
+	"github.com/cencori/cencori-go/vectorstore"
+)
+
+// keywordEmbedder embeds text as keyword counts so that retrieval is predictable.
+type keywordEmbedder struct{}
+
+var keywords = []string{"goroutine", "channel", "coffee"}
+
+func embedKeywords(text string) []float32 {
+	v := make([]float32, len(keywords))
+	for i, k := range keywords {
+		v[i] = float32(strings.Count(strings.ToLower(text), k))
+	}
+	return v
+}
+
+func (keywordEmbedder) Embeddings(_ context.Context, params cencori.EmbeddingParams, _ ...cencori.RequestOption) (*cencori.EmbeddingResponse, error) {
+	resp := &cencori.EmbeddingResponse{}
+	for i, text := range params.Input.Texts() {
+		resp.Data = append(resp.Data, cencori.EmbeddingData{Embedding: embedKeywords(text), Index: i})
+	}
+	return resp, nil
+}
+
+func (e keywordEmbedder) EmbedMany(ctx context.Context, params cencori.EmbedManyParams, _ ...cencori.RequestOption) (*cencori.EmbeddingResponse, error) {
+	return e.Embeddings(ctx, cencori.EmbeddingParams{Input: cencori.EmbeddingTexts(params.Inputs)})
+}
+
+type fakeGenerator struct {
+	answer string
+	params *cencori.ChatParams
+}
+
+func (g *fakeGenerator) Create(_ context.Context, params *cencori.ChatParams, _ ...cencori.RequestOption) (*cencori.ChatResponse, error) {
+	g.params = params
+	resp := &cencori.ChatResponse{}
+	resp.Choices = append(resp.Choices, struct {
+		Index        int             `json:"index"`
+		Message      cencori.Message `json:"message"`
+		FinishReason string          `json:"finish_reason"`
+	}{Message: cencori.Message{Role: "assistant", Content: g.answer}})
+	return resp, nil
+}
+
+func TestPipeline_Ask(t *testing.T) {
+	retriever := &VectorRetriever{
+		Embedder: keywordEmbedder{},
+		Model:    "text-embedding-3-small",
+		Store:    vectorstore.New(vectorstore.Options{}),
+	}
+
+	var chunks []Chunk
+	for _, doc := range []Document{
+		{ID: "go.md", Text: "# Goroutines\n\nA goroutine is a lightweight thread."},
+		{ID: "chan.md", Text: "# Channels\n\nA channel connects goroutine workers. Channel sends block."},
+		{ID: "cafe.txt", Text: "Coffee is brewed from roasted beans."},
+	} {
+		chunks = append(chunks, Chunker{}.Split(doc)...)
+	}
+	if err := retriever.Index(context.Background(), chunks); err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+
+	gen := &fakeGenerator{answer: "Goroutines are lightweight threads [1]. They talk over channels [1, 2]. Ignore [9]."}
+	p := &Pipeline{Generator: gen, Retriever: retriever, Model: "gpt-4o", TopK: 2}
+
+	answer, err := p.Ask(context.Background(), "How do goroutine and channel work?")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+
+	if len(answer.Sources) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(answer.Sources))
+	}
+	for _, s := range answer.Sources {
+		if s.DocumentID == "cafe.txt" {
+			t.Errorf("irrelevant document retrieved: %+v", s)
+		}
+	}
+
+	prompt := gen.params.Messages[1].Content
+	if !strings.Contains(prompt, "[1] "+answer.Sources[0].DocumentID) || !strings.Contains(prompt, "Question: How do goroutine") {
+		t.Errorf("prompt missing numbered sources or question:\n%s", prompt)
+	}
+	if gen.params.Messages[0].Content != DefaultSystemPrompt {
+		t.Errorf("expected default system prompt, got %q", gen.params.Messages[0].Content)
+	}
+
+	if len(answer.Citations) != 2 {
+		t.Fatalf("expected 2 citations, got %+v", answer.Citations)
+	}
+	first, second := answer.Citations[0], answer.Citations[1]
+	if got := answer.Text[first.Start:first.End]; got != "Goroutines are lightweight threads" {
+		t.Errorf("first span = %q", got)
+	}
+	if got := answer.Text[second.Start:second.End]; got != "They talk over channels" {
+		t.Errorf("second span = %q", got)
+	}
+	if len(second.ChunkIDs) != 2 || second.ChunkIDs[0] != answer.Sources[0].ID || second.ChunkIDs[1] != answer.Sources[1].ID {
+		t.Errorf("second citation chunk IDs = %v", second.ChunkIDs)
+	}
+}
+
+func TestPromptBuilder_MaxContextTokens(t *testing.T) {
+	retrieved := []Retrieved{
+		{Chunk: Chunk{ID: "a#0", DocumentID: "a", Text: strings.Repeat("x", 400)}},
+		{Chunk: Chunk{ID: "b#0", DocumentID: "b", Text: strings.Repeat("y", 400)}},
+	}
+
+	_, sources := PromptBuilder{MaxContextTokens: 150}.Build("q", retrieved)
+	if len(sources) != 1 || sources[0].ID != "a#0" {
+		t.Errorf("expected only the top source to fit, got %+v", sources)
+	}
+}
@@ -0,0 +1,72 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cencori/cencori-go"
+)
+
+// DefaultSystemPrompt instructs the model to answer from the numbered sources
+// and cite them with bracketed numbers.
+const DefaultSystemPrompt = "Answer the question using only the numbered sources provided. " +
+	"Cite the sources that support each sentence with their numbers in square brackets, for example [1] or [2][3]. " +
+	"If the sources do not contain the answer, say that you don't know."
+
+// PromptBuilder turns a question and retrieved chunks into chat messages.
+type PromptBuilder struct {
+	// SystemPrompt replaces DefaultSystemPrompt.
+	SystemPrompt string
+	// MaxContextTokens caps the size of the sources block (default 3000).
+	// Lower-ranked sources that do not fit are left out.
+	MaxContextTokens int
+	// TokenCounter estimates tokens (default: one token per four bytes).
+	TokenCounter func(string) int
+}
+
+// Build returns the messages for question and the sources included in them,
+// in the order they are numbered (source n is sources[n-1]).
+func (b PromptBuilder) Build(question string, retrieved []Retrieved) ([]cencori.Message, []Retrieved) {
+	if b.SystemPrompt == "" {
+		b.SystemPrompt = DefaultSystemPrompt
+	}
+	if b.MaxContextTokens <= 0 {
+		b.MaxContextTokens = 3000
+	}
+	if b.TokenCounter == nil {
+		b.TokenCounter = estimateTokens
+	}
+
+	var (
+		ctx     strings.Builder
+		sources []Retrieved
+		tokens  int
+	)
+	for _, r := range retrieved {
+		block := formatSource(len(sources)+1, r)
+		n := b.TokenCounter(block)
+		if len(sources) > 0 && tokens+n > b.MaxContextTokens {
+			break
+		}
+		ctx.WriteString(block)
+		tokens += n
+		sources = append(sources, r)
+	}
+
+	user := fmt.Sprintf("Sources:\n\n%s\nQuestion: %s", ctx.String(), question)
+	return []cencori.Message{
+		{Role: "system", Content: b.SystemPrompt},
+		{Role: "user", Content: user},
+	}, sources
+}
+
+func formatSource(n int, r Retrieved) string {
+	label := r.DocumentID
+	if r.Heading != "" {
+		label += " › " + r.Heading
+	}
+	if label == "" {
+		label = r.ID
+	}
+	return fmt.Sprintf("[%d] %s\n%s\n\n", n, label, r.Text)
+}
@@ -0,0 +1,120 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+
+	"github.com/cencori/cencori-go"
+	"github.com/cencori/cencori-go/vectorstore"
+)
+
+// Retrieved is a chunk returned by a Retriever with its relevance score.
+type Retrieved struct {
+	Chunk
+	Score float64
+}
+
+// Retriever finds the chunks most relevant to a query.
+type Retriever interface {
+	Retrieve(ctx context.Context, query string, k int) ([]Retrieved, error)
+}
+
+// Embedder creates embeddings. *cencori.ChatService satisfies it.
+type Embedder interface {
+	Embeddings(ctx context.Context, params cencori.EmbeddingParams, opts ...cencori.RequestOption) (*cencori.EmbeddingResponse, error)
+	EmbedMany(ctx context.Context, params cencori.EmbedManyParams, opts ...cencori.RequestOption) (*cencori.EmbeddingResponse, error)
+}
+
+var _ Embedder = (*cencori.ChatService)(nil)
+
+// VectorRetriever retrieves chunks by embedding similarity using a vectorstore.Store.
+type VectorRetriever struct {
+	Embedder Embedder
+	// Model is the embedding model used for both chunks and queries.
+	Model string
+	Store *vectorstore.Store
+	// Filter optionally restricts which chunks can be retrieved.
+	Filter vectorstore.Filter
+}
+
+// Metadata keys VectorRetriever uses to round-trip chunk fields through the store.
+const (
+	metaDocumentID = "rag.document_id"
+	metaIndex      = "rag.index"
+	metaHeading    = "rag.heading"
+)
+
+// Index embeds chunks and adds them to the store.
+func (r *VectorRetriever) Index(ctx context.Context, chunks []Chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	inputs := make([]string, len(chunks))
+	docs := make([]vectorstore.Document, len(chunks))
+	for i, c := range chunks {
+		inputs[i] = c.Text
+		meta := maps.Clone(c.Metadata)
+		if meta == nil {
+			meta = make(map[string]string, 3)
+		}
+		meta[metaDocumentID] = c.DocumentID
+		meta[metaIndex] = strconv.Itoa(c.Index)
+		if c.Heading != "" {
+			meta[metaHeading] = c.Heading
+		}
+		docs[i] = vectorstore.Document{ID: c.ID, Content: c.Text, Metadata: meta}
+	}
+
+	resp, err := r.Embedder.EmbedMany(ctx, cencori.EmbedManyParams{Model: r.Model, Inputs: inputs})
+	if err != nil {
+		return fmt.Errorf("rag: embed chunks: %w", err)
+	}
+	if err := r.Store.AddEmbeddings(resp, docs); err != nil {
+		return fmt.Errorf("rag: index chunks: %w", err)
+	}
+	return nil
+}
+
+// Retrieve implements Retriever.
+func (r *VectorRetriever) Retrieve(ctx context.Context, query string, k int) ([]Retrieved, error) {
+	resp, err := r.Embedder.Embeddings(ctx, cencori.EmbeddingParams{
+		Input: cencori.EmbeddingText(query),
+		Model: r.Model,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rag: embed query: %w", err)
+	}
+	if len(resp.Data) == 0 {
+		return nil, errors.New("rag: embed query: no embedding returned")
+	}
+
+	results, err := r.Store.Search(resp.Data[0].Embedding, k, r.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("rag: search: %w", err)
+	}
+
+	out := make([]Retrieved, len(results))
+	for i, res := range results {
+		meta := maps.Clone(res.Metadata)
+		index, _ := strconv.Atoi(meta[metaIndex]) //nolint:errcheck // Missing or malformed indexes default to 0.
+		chunk := Chunk{
+			ID:         res.ID,
+			DocumentID: meta[metaDocumentID],
+			Index:      index,
+			Text:       res.Content,
+			Heading:    meta[metaHeading],
+		}
+		delete(meta, metaDocumentID)
+		delete(meta, metaIndex)
+		delete(meta, metaHeading)
+		if len(meta) > 0 {
+			chunk.Metadata = meta
+		}
+		out[i] = Retrieved{Chunk: chunk, Score: res.Score}
+	}
+	return out, nil
+}
@@ -0,0 +1,335 @@
+package cencori
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PIIKind names a kind of personal data. It is used in placeholders, e.g. "[EMAIL_1]".
+type PIIKind string
+
+const (
+	PIIEmail      PIIKind = "EMAIL"
+	PIIPhone      PIIKind = "PHONE"
+	PIICreditCard PIIKind = "CREDIT_CARD"
+	PIIIBAN       PIIKind = "IBAN"
+)
+
+// PIIPattern detects one kind of personal data.
+type PIIPattern struct {
+	// Kind names the data; it must consist of upper-case letters, digits and underscores.
+	Kind    PIIKind
+	Pattern *regexp.Regexp
+	// Valid, if set, rejects matches that are not really of this kind, e.g.
+	// by checking a checksum.
+	Valid func(match string) bool
+}
+
+var (
+	emailPattern = regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)
+	ibanPattern  = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`)
+	cardPattern  = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	phonePattern = regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{1,4}\)[ .-]?)?\b\d{2,4}(?:[ .-]?\d{2,4}){1,4}\b`)
+	datePattern  = regexp.MustCompile(`^\d{4}[-./]\d{1,2}[-./]\d{1,2}$`)
+
+	placeholderPattern = regexp.MustCompile(`\[([A-Z][A-Z0-9_]*)_(\d+)\]`)
+)
+
+// builtinPII returns the built-in detectors. Order matters: card numbers are
+// redacted before phone numbers so that they are not mistaken for them.
+func builtinPII() []PIIPattern {
+	return []PIIPattern{
+		{Kind: PIIEmail, Pattern: emailPattern},
+		{Kind: PIIIBAN, Pattern: ibanPattern, Valid: validIBAN},
+		{Kind: PIICreditCard, Pattern: cardPattern, Valid: validLuhn},
+		{Kind: PIIPhone, Pattern: phonePattern, Valid: validPhone},
+	}
+}
+
+func digitsOf(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// validLuhn reports whether the digits of s pass the Luhn checksum.
+func validLuhn(s string) bool {
+	digits := digitsOf(s)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range len(digits) {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validIBAN reports whether s passes the IBAN mod-97 check.
+func validIBAN(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	var b strings.Builder
+	for _, r := range s[4:] + s[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			b.WriteString(strconv.Itoa(int(r-'A') + 10))
+		default:
+			return false
+		}
+	}
+	n, ok := new(big.Int).SetString(b.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func validPhone(s string) bool {
+	n := len(digitsOf(s))
+	return n >= 7 && n <= 15 && !datePattern.MatchString(s)
+}
+
+// RedactionConfig configures a Redactor.
+type RedactionConfig struct {
+	// Kinds selects the built-in detectors (default: all of them).
+	Kinds []PIIKind
+	// Patterns adds custom detectors, applied after the built-in ones.
+	Patterns []PIIPattern
+	// KeepRedacted leaves placeholders in responses instead of restoring the
+	// original values.
+	KeepRedacted bool
+}
+
+// Redactor replaces personal data in prompts with placeholders such as
+// "[EMAIL_1]" before they leave the process, and restores the original values
+// in responses. Install it with WithMiddleware(redactor.Middleware()).
+type Redactor struct {
+	patterns     []PIIPattern
+	keepRedacted bool
+}
+
+// NewRedactor creates a Redactor.
+func NewRedactor(cfg RedactionConfig) *Redactor {
+	r := &Redactor{keepRedacted: cfg.KeepRedacted}
+	for _, p := range builtinPII() {
+		if len(cfg.Kinds) == 0 || slices.Contains(cfg.Kinds, p.Kind) {
+			r.patterns = append(r.patterns, p)
+		}
+	}
+	r.patterns = append(r.patterns, cfg.Patterns...)
+	return r
+}
+
+// PIIVault records the values replaced during one request so that they can
+// be restored. It is safe for concurrent use.
+type PIIVault struct {
+	mu            sync.Mutex
+	byValue       map[string]string
+	byPlaceholder map[string]string
+	counts        map[PIIKind]int
+}
+
+// NewPIIVault creates an empty PIIVault.
+func NewPIIVault() *PIIVault {
+	return &PIIVault{
+		byValue:       make(map[string]string),
+		byPlaceholder: make(map[string]string),
+		counts:        make(map[PIIKind]int),
+	}
+}
+
+func (v *PIIVault) placeholder(kind PIIKind, value string) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key := string(kind) + "\x00" + value
+	if p, ok := v.byValue[key]; ok {
+		return p
+	}
+	v.counts[kind]++
+	p := fmt.Sprintf("[%s_%d]", kind, v.counts[kind])
+	v.byValue[key] = p
+	v.byPlaceholder[p] = value
+	return p
+}
+
+// Len returns the number of distinct values recorded.
+func (v *PIIVault) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.byPlaceholder)
+}
+
+// Rehydrate replaces the placeholders in text with the values they stand for.
+// Unknown placeholders are left as they are.
+func (v *PIIVault) Rehydrate(text string) string {
+	if !strings.Contains(text, "[") {
+		return text
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return placeholderPattern.ReplaceAllStringFunc(text, func(p string) string {
+		if value, ok := v.byPlaceholder[p]; ok {
+			return value
+		}
+		return p
+	})
+}
+
+// Redact replaces the personal data in text with placeholders recorded in vault.
+// The same value always maps to the same placeholder within a vault.
+func (r *Redactor) Redact(text string, vault *PIIVault) string {
+	for _, p := range r.patterns {
+		text = p.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if p.Valid != nil && !p.Valid(match) {
+				return match
+			}
+			return vault.placeholder(p.Kind, match)
+		})
+	}
+	return text
+}
+
+func (r *Redactor) redactMessages(messages []Message, vault *PIIVault) []Message {
+	out := make([]Message, len(messages))
+	for i, m := range messages {
+		out[i] = Message{Role: m.Role, Content: r.Redact(m.Content, vault)}
+	}
+	return out
+}
+
+// Middleware returns the middleware that redacts Chat.Create, Chat.Stream and
+// Chat.Embeddings requests and rehydrates chat responses.
+func (r *Redactor) Middleware() Middleware {
+	return Middleware{
+		Create: func(next CreateFunc) CreateFunc {
+			return func(ctx context.Context, params *ChatParams) (*ChatResponse, error) {
+				vault := NewPIIVault()
+				redacted := *params
+				redacted.Messages = r.redactMessages(params.Messages, vault)
+
+				resp, err := next(ctx, &redacted)
+				if err != nil || r.keepRedacted || vault.Len() == 0 {
+					return resp, err
+				}
+				for i := range resp.Choices {
+					resp.Choices[i].Message.Content = vault.Rehydrate(resp.Choices[i].Message.Content)
+				}
+				return resp, nil
+			}
+		},
+		Stream: func(next StreamFunc) StreamFunc {
+			return func(ctx context.Context, params *ChatParams) (<-chan StreamChunk, error) {
+				vault := NewPIIVault()
+				redacted := *params
+				redacted.Messages = r.redactMessages(params.Messages, vault)
+
+				chunks, err := next(ctx, &redacted)
+				if err != nil || r.keepRedacted || vault.Len() == 0 {
+					return chunks, err
+				}
+				return rehydrateStream(chunks, vault), nil
+			}
+		},
+		Embeddings: func(next EmbeddingsFunc) EmbeddingsFunc {
+			return func(ctx context.Context, params EmbeddingParams) (*EmbeddingResponse, error) {
+				if texts := params.Input.Texts(); len(texts) > 0 {
+					vault := NewPIIVault()
+					redacted := make([]string, len(texts))
+					for i, t := range texts {
+						redacted[i] = r.Redact(t, vault)
+					}
+					params.Input.texts = redacted
+				}
+				return next(ctx, params)
+			}
+		},
+	}
+}
+
+// maxPlaceholderLen bounds how much streamed text is held back while waiting
+// for a placeholder split across chunks to complete.
+const maxPlaceholderLen = 48
+
+// rehydrateStream restores placeholders in streamed deltas. A placeholder may
+// be split across chunks, so text from an unterminated "[" onwards is held
+// back until it completes, the choice finishes or the stream ends.
+func rehydrateStream(in <-chan StreamChunk, vault *PIIVault) <-chan StreamChunk {
+	out := make(chan StreamChunk)
+	go func() {
+		defer close(out)
+		pending := make(map[int]string)
+		var last StreamChunk
+
+		// flush emits text still held back, e.g. when the stream ends without
+		// finish reasons or fails.
+		flush := func() {
+			var choices []StreamChoice
+			for index, text := range pending {
+				if text != "" {
+					choices = append(choices, StreamChoice{Index: index, Delta: StreamDelta{Content: vault.Rehydrate(text)}})
+				}
+			}
+			clear(pending)
+			if len(choices) > 0 {
+				slices.SortFunc(choices, func(a, b StreamChoice) int { return a.Index - b.Index })
+				out <- StreamChunk{ID: last.ID, Object: last.Object, Created: last.Created, Model: last.Model, Choices: choices}
+			}
+		}
+
+		for chunk := range in {
+			if chunk.Err != nil {
+				flush()
+				out <- chunk
+				continue
+			}
+			last = chunk
+			for i := range chunk.Choices {
+				c := &chunk.Choices[i]
+				text := pending[c.Index] + c.Delta.Content
+				hold := heldSuffix(text)
+				if c.FinishReason != nil {
+					hold = 0
+				}
+				pending[c.Index] = text[len(text)-hold:]
+				c.Delta.Content = vault.Rehydrate(text[:len(text)-hold])
+			}
+			out <- chunk
+		}
+		flush()
+	}()
+	return out
+}
+
+// heldSuffix returns the length of the tail of text that may be the start of
+// a placeholder.
+func heldSuffix(text string) int {
+	i := strings.LastIndexByte(text, '[')
+	if i < 0 || strings.IndexByte(text[i:], ']') >= 0 || len(text)-i > maxPlaceholderLen {
+		return 0
+	}
+	for _, r := range text[i+1:] {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+			return 0
+		}
+	}
+	return len(text) - i
+}
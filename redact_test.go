@@ -0,0 +1,116 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	r := NewRedactor(RedactionConfig{
+		Patterns: []PIIPattern{{Kind: "EMPLOYEE_ID", Pattern: regexp.MustCompile(`\bEMP-\d{5}\b`)}},
+	})
+	vault := NewPIIVault()
+
+	in := "Mail jane@example.com or jane@example.com, call +1 (555) 123-4567, " +
+		"card 4111 1111 1111 1111, not 4111 1111 1111 1112, IBAN GB82 WEST 1234 5698 7654 32, " +
+		"on 2024-01-15, employee EMP-12345."
+	got := r.Redact(in, vault)
+	want := "Mail [EMAIL_1] or [EMAIL_1], call [PHONE_1], " +
+		"card [CREDIT_CARD_1], not 4111 1111 1111 1112, IBAN [IBAN_1], " +
+		"on 2024-01-15, employee [EMPLOYEE_ID_1]."
+	if got != want {
+		t.Errorf("Redact:\n got %q\nwant %q", got, want)
+	}
+	if back := vault.Rehydrate(got); back != in {
+		t.Errorf("Rehydrate:\n got %q\nwant %q", back, in)
+	}
+	if vault.Rehydrate("[EMAIL_9]") != "[EMAIL_9]" {
+		t.Error("unknown placeholders should be left alone")
+	}
+}
+
+func TestRedactor_Kinds(t *testing.T) {
+	r := NewRedactor(RedactionConfig{Kinds: []PIIKind{PIIEmail}})
+	got := r.Redact("a@b.io 555-123-4567", NewPIIVault())
+	if got != "[EMAIL_1] 555-123-4567" {
+		t.Errorf("expected only emails to be redacted, got %q", got)
+	}
+}
+
+func TestRedactor_Middleware(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params ChatParams
+		json.NewDecoder(r.Body).Decode(&params)
+		sent = append(sent, params.Messages[0].Content)
+
+		if !params.Stream {
+			resp := ChatResponse{}
+			resp.Choices = append(resp.Choices, struct {
+				Index        int     `json:"index"`
+				Message      Message `json:"message"`
+				FinishReason string  `json:"finish_reason"`
+			}{Message: Message{Role: "assistant", Content: "Sent to [EMAIL_1]."}})
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, part := range []string{"Sent to [EMA", "IL_", "1]", " and [EM"} {
+			data, _ := json.Marshal(StreamChunk{Choices: []StreamChoice{{Delta: StreamDelta{Content: part}}}})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	redactor := NewRedactor(RedactionConfig{})
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithMiddleware(redactor.Middleware()))
+
+	params := &ChatParams{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "Email jane@example.com"}}}
+	resp, err := client.Chat.Create(context.Background(), params)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if sent[0] != "Email [EMAIL_1]" {
+		t.Errorf("expected the email to be redacted upstream, got %q", sent[0])
+	}
+	if resp.Choices[0].Message.Content != "Sent to jane@example.com." {
+		t.Errorf("expected the response to be rehydrated, got %q", resp.Choices[0].Message.Content)
+	}
+	if params.Messages[0].Content != "Email jane@example.com" {
+		t.Error("the caller's params must not be modified")
+	}
+
+	chunks, err := client.Chat.Stream(context.Background(), params)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var text strings.Builder
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			t.Fatalf("unexpected stream error: %v", chunk.Err)
+		}
+		for _, c := range chunk.Choices {
+			text.WriteString(c.Delta.Content)
+		}
+	}
+	if text.String() != "Sent to jane@example.com and [EM" {
+		t.Errorf("expected split placeholders to be rehydrated, got %q", text.String())
+	}
+}
+
+func TestValidLuhnAndIBAN(t *testing.T) {
+	if !validLuhn("5500-0000-0000-0004") || validLuhn("5500-0000-0000-0005") {
+		t.Error("Luhn check failed")
+	}
+	if !validIBAN("DE89370400440532013000") || validIBAN("DE89370400440532013001") {
+		t.Error("IBAN check failed")
+	}
+}
@@ -0,0 +1,228 @@
+package cencori
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestOption configures a single API call. Pass request options as the
+// trailing arguments of a service method.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	header         http.Header
+	timeout        time.Duration
+	apiKey         string
+	idempotencyKey string
+	baseURL        string
+	retry          *RetryPolicy
+	responseInto   *ResponseMeta
+	responseMu     *sync.Mutex
+}
+
+// ResponseMeta describes the HTTP response to a single API call.
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+	// RequestID is the gateway request ID; quote it when contacting support.
+	RequestID string
+	// Provider is the upstream AI provider that served the request, when the
+	// gateway reports it.
+	Provider string
+	// Latency is the time from sending the request until the response body
+	// was read, or until the response headers arrived for streams.
+	Latency time.Duration
+	// Body is the raw response body; nil for streams.
+	Body []byte
+}
+
+// providerHeader is the response header the gateway reports the upstream provider in.
+const providerHeader = "X-Cencori-Provider"
+
+// WithResponseInto stores metadata about the final HTTP response in meta,
+// including for error responses. meta is left unchanged if no response was
+// received, as for cache hits. Requests the SDK sends on its own behalf, such
+// as moderation pre-flight checks, are not recorded. For calls that send
+// several requests, such as CreateMany, meta describes the last response
+// received.
+func WithResponseInto(meta *ResponseMeta) RequestOption {
+	return func(o *requestOptions) {
+		o.responseInto = meta
+		o.responseMu = new(sync.Mutex)
+	}
+}
+
+// WithHeader adds a header to the request, replacing any value the client
+// would send for the same name.
+func WithHeader(name, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Set(name, value)
+	}
+}
+
+// WithRequestTimeout replaces the client timeout for this call. It applies
+// to each attempt, including reading the response body.
+func WithRequestTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) { o.timeout = d }
+}
+
+// WithRequestAPIKey authenticates this call with key instead of the client's API key.
+func WithRequestAPIKey(key string) RequestOption {
+	return func(o *requestOptions) { o.apiKey = key }
+}
+
+// WithIdempotencyKey sends key in the Idempotency-Key header so that the
+// gateway performs a retried request at most once. Mutating calls on
+// ProjectsService and APIKeysService generate a key when none is given; pass
+// your own to make retries across separate calls safe as well.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) { o.idempotencyKey = key }
+}
+
+// withIdempotencyKey ensures the call in ctx sends an idempotency key,
+// generating one unless the caller supplied it. The key is reused for every
+// retry and failover attempt of the call.
+func withIdempotencyKey(ctx context.Context) context.Context {
+	if o := requestOptionsFrom(ctx); o != nil && o.idempotencyKey != "" {
+		return ctx
+	}
+	return withRequestOptions(ctx, []RequestOption{WithIdempotencyKey(newIdempotencyKey())})
+}
+
+// newIdempotencyKey returns a random version 4 UUID.
+func newIdempotencyKey() string {
+	var b [16]byte
+	rand.Read(b[:]) //nolint:errcheck // crypto/rand.Read never returns an error.
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// WithRequestBaseURL sends this call to baseURL, bypassing endpoint failover.
+func WithRequestBaseURL(baseURL string) RequestOption {
+	return func(o *requestOptions) { o.baseURL = baseURL }
+}
+
+// WithRequestRetryPolicy replaces the client retry policy for this call. A
+// zero RetryPolicy disables retries.
+func WithRequestRetryPolicy(p RetryPolicy) RequestOption {
+	return func(o *requestOptions) { o.retry = &p }
+}
+
+type requestOptionsKey struct{}
+
+// withRequestOptions records opts in ctx, on top of any options already there.
+func withRequestOptions(ctx context.Context, opts []RequestOption) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+	o := &requestOptions{}
+	if prev := requestOptionsFrom(ctx); prev != nil {
+		*o = *prev
+		o.header = prev.header.Clone()
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return context.WithValue(ctx, requestOptionsKey{}, o)
+}
+
+// forInternalRequest returns ctx for a request the SDK sends on its own
+// behalf in the middle of a call, such as a moderation check. The request
+// keeps the caller's API key, base URL and headers but neither fills the
+// WithResponseInto target nor reuses the caller's idempotency key, which
+// belongs to the caller's own request.
+func forInternalRequest(ctx context.Context) context.Context {
+	ctx = withoutIdempotencyKey(ctx)
+	if o := requestOptionsFrom(ctx); o != nil {
+		o.responseInto, o.responseMu = nil, nil
+	}
+	return ctx
+}
+
+// withoutIdempotencyKey returns a copy of the options in ctx without the
+// caller's idempotency key, for the sub-calls of an operation that sends
+// several different requests.
+func withoutIdempotencyKey(ctx context.Context) context.Context {
+	prev := requestOptionsFrom(ctx)
+	if prev == nil {
+		return ctx
+	}
+	o := *prev
+	o.idempotencyKey = ""
+	o.header = prev.header.Clone()
+	o.header.Del("Idempotency-Key")
+	return context.WithValue(ctx, requestOptionsKey{}, &o)
+}
+
+func requestOptionsFrom(ctx context.Context) *requestOptions {
+	o, _ := ctx.Value(requestOptionsKey{}).(*requestOptions) //nolint:errcheck // A missing value yields nil.
+	return o
+}
+
+// requestHeaders applies the header overrides of the call in ctx to header.
+func requestHeaders(ctx context.Context, header http.Header) http.Header {
+	o := requestOptionsFrom(ctx)
+	if o == nil {
+		return header
+	}
+	if o.apiKey != "" {
+		header.Set("CENCORI_API_KEY", o.apiKey)
+	}
+	if o.idempotencyKey != "" {
+		header.Set("Idempotency-Key", o.idempotencyKey)
+	}
+	for name, values := range o.header {
+		header[name] = values
+	}
+	return header
+}
+
+// httpClientFor returns the HTTP client for the call in ctx.
+func (c *Client) httpClientFor(ctx context.Context) *http.Client {
+	o := requestOptionsFrom(ctx)
+	if o == nil || o.timeout <= 0 {
+		return c.httpClient
+	}
+	hc := *c.httpClient
+	hc.Timeout = o.timeout
+	return &hc
+}
+
+// recordResponse fills the ResponseMeta requested for the call, if any. It
+// buffers the body of non-streamed responses so the caller can still read it.
+func recordResponse(ctx context.Context, resp *http.Response, start time.Time) {
+	o := requestOptionsFrom(ctx)
+	if o == nil || o.responseInto == nil {
+		return
+	}
+	meta := ResponseMeta{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		RequestID:  requestID(resp.Header),
+		Provider:   resp.Header.Get(providerHeader),
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		resp.Body.Close() //nolint:errcheck // The body has been read into memory.
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err == nil {
+			meta.Body = body
+		}
+	}
+	meta.Latency = time.Since(start)
+
+	o.responseMu.Lock()
+	defer o.responseMu.Unlock()
+	*o.responseInto = meta
+}
@@ -0,0 +1,208 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithResponseInto(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-42")
+		w.Header().Set("X-Cencori-Provider", "openai")
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123"})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	var meta ResponseMeta
+	resp, err := client.Chat.Create(context.Background(), &ChatParams{
+		Model:    "gpt-4o",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	}, WithResponseInto(&meta))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resp.ID != "chat-123" {
+		t.Errorf("expected the response to still decode, got ID %q", resp.ID)
+	}
+	if meta.StatusCode != http.StatusOK || meta.RequestID != "req-42" || meta.Provider != "openai" {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+	if meta.Latency <= 0 {
+		t.Errorf("expected a positive latency, got %v", meta.Latency)
+	}
+	if !strings.Contains(string(meta.Body), `"id":"chat-123"`) {
+		t.Errorf("expected the raw body, got %q", meta.Body)
+	}
+}
+
+func TestWithResponseInto_SkipsInternalRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v1/embeddings":
+			var params EmbeddingParams
+			json.NewDecoder(r.Body).Decode(&params)
+			vector := []float32{1, 0}
+			if data, _ := json.Marshal(params.Input); strings.Contains(string(data), "attack") {
+				vector = []float32{0, 1}
+			}
+			json.NewEncoder(w).Encode(EmbeddingResponse{Data: []EmbeddingData{{Embedding: vector}}})
+		case "/api/v1/moderations":
+			var params ModerationParams
+			json.NewDecoder(r.Body).Decode(&params)
+			flagged := strings.Contains(params.Input[0].Text, "attack")
+			json.NewEncoder(w).Encode(ModerationResponse{Results: []ModerationResult{{Flagged: flagged}}})
+		default:
+			json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123"})
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithModeration(ModerationConfig{}))
+	client.Use(NewSemanticCache(SemanticCacheConfig{Embedder: client.Chat}).Middleware())
+
+	params := func(content string) *ChatParams {
+		return &ChatParams{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: content}}}
+	}
+
+	var meta ResponseMeta
+	if _, err := client.Chat.Create(context.Background(), params("Hi"), WithResponseInto(&meta)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if meta.RequestID != "/api/ai/chat" {
+		t.Errorf("expected the chat response, got request ID %q", meta.RequestID)
+	}
+
+	meta = ResponseMeta{}
+	resp, err := client.Chat.Create(context.Background(), params("Hi"), WithResponseInto(&meta))
+	if err != nil || !resp.CacheHit {
+		t.Fatalf("expected a semantic cache hit, got %+v, %v", resp, err)
+	}
+	if meta.StatusCode != 0 {
+		t.Errorf("expected no metadata for a cache hit, got request ID %q", meta.RequestID)
+	}
+
+	meta = ResponseMeta{}
+	if _, err := client.Chat.Create(context.Background(), params("attack"), WithResponseInto(&meta)); !errors.Is(err, ErrContentFiltered) {
+		t.Fatalf("expected ErrContentFiltered, got %v", err)
+	}
+	if meta.StatusCode != 0 {
+		t.Errorf("expected no metadata for a screened request, got request ID %q", meta.RequestID)
+	}
+}
+
+func TestWithResponseInto_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Cencori-Request-Id", "req-99")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"project not found","code":"NOT_FOUND"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	var meta ResponseMeta
+	_, err := client.Projects.Get(context.Background(), "org", "missing", WithResponseInto(&meta))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.RequestID != "req-99" || !strings.Contains(err.Error(), "request_id: req-99") {
+		t.Errorf("expected the request ID in the error, got %q", err)
+	}
+	if meta.StatusCode != http.StatusNotFound || meta.RequestID != "req-99" {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+	if !strings.Contains(string(meta.Body), "project not found") {
+		t.Errorf("expected the raw error body, got %q", meta.Body)
+	}
+}
+
+func TestWithResponseInto_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("X-Request-Id", "req-7")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+
+	var meta ResponseMeta
+	chunks, err := client.Chat.Stream(context.Background(), &ChatParams{
+		Model:    "gpt-4o",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	}, WithResponseInto(&meta))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var content string
+	for chunk := range chunks {
+		content += chunk.Choices[0].Delta.Content
+	}
+	if content != "Hi" {
+		t.Errorf("expected the stream to be readable, got %q", content)
+	}
+	if meta.RequestID != "req-7" || meta.Body != nil {
+		t.Errorf("expected a request ID and no body for streams, got %+v", meta)
+	}
+}
+
+func TestRequestOptions_Overrides(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, want := range map[string]string{
+			"CENCORI_API_KEY": "other-key",
+			"Idempotency-Key": "idem-1",
+			"X-Team":          "search",
+		} {
+			if got := r.Header.Get(name); got != want {
+				t.Errorf("expected %s %q, got %q", name, want, got)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"projects": []Project{}})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(unreachableURL()))
+
+	_, err := client.Projects.List(context.Background(), "org",
+		WithRequestBaseURL(server.URL),
+		WithRequestAPIKey("other-key"),
+		WithIdempotencyKey("idem-1"),
+		WithHeader("X-Team", "search"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestRequestOptions_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		json.NewEncoder(w).Encode(MetricsResponse{})
+	}))
+	defer server.Close()
+
+	short, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	if _, err := short.Metrics.Get(context.Background(), "24h", WithRequestTimeout(20*time.Millisecond)); err == nil {
+		t.Error("expected the per-call timeout to fail the request")
+	}
+
+	long, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL), WithTimeout(20*time.Millisecond))
+	if _, err := long.Metrics.Get(context.Background(), "24h", WithRequestTimeout(time.Second)); err != nil {
+		t.Errorf("expected the per-call timeout to replace the client timeout, got %v", err)
+	}
+}
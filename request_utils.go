@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 const maxResponseSize = 10 * 1024 * 1024
@@ -18,13 +19,132 @@ func handleError(resp *http.Response) error {
 		return &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    string(body),
+			RequestID:  requestID(resp.Header),
 		}
 	}
 	apiErr.StatusCode = resp.StatusCode
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = requestID(resp.Header)
+	}
 	apiErr.fillSentinel() // Attach the ErrInvalidAPIKey etc.
 	return &apiErr
 }
 
+// headers returns the headers sent with every API request. send adds the
+// API key.
+func (c *Client) headers() http.Header {
+	h := make(http.Header)
+	h.Set("Content-Type", "application/json")
+	return h
+}
+
+// baseURLs returns the base URLs to try, in order, and the endpoint pool
+// tracking them. The pool is nil without failover or when the call
+// overrides the base URL.
+func (c *Client) baseURLs(ctx context.Context) ([]string, *endpointPool) {
+	if o := requestOptionsFrom(ctx); o != nil && o.baseURL != "" {
+		return []string{o.baseURL}, nil
+	}
+	if c.endpoints == nil {
+		return []string{c.BaseURL}, nil
+	}
+	return c.endpoints.order(), c.endpoints
+}
+
+// send executes an HTTP request, failing over to the next endpoint when the
+// current one cannot be reached and retrying transient failures according
+// to the retry policy. The caller must close the response body.
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, header http.Header) (*http.Response, error) {
+	start := time.Now()
+	policy := c.retryPolicy(ctx)
+
+	var attempts int
+	for retry := 0; ; retry++ {
+		// The key is looked up for every attempt so that rotation takes
+		// effect for retries too.
+		key, err := c.apiKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		header.Set("CENCORI_API_KEY", key)
+		header = requestHeaders(ctx, header)
+
+		resp, err := c.attempt(ctx, method, path, payload, header, &attempts)
+		if policy == nil || retry >= policy.MaxRetries || ctx.Err() != nil || !policy.shouldRetry(resp, err) {
+			if err == nil {
+				recordResponse(ctx, resp, start)
+			}
+			return resp, err
+		}
+
+		wait := policy.backoff(retry, resp)
+		if resp != nil {
+			discard(resp)
+		}
+		if info := callInfoFrom(ctx); info != nil {
+			c.recordRetry(info.Operation, info.Model)
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// attempt sends the request once to each base URL in turn until one can be
+// reached. attempts counts the requests sent for logging.
+func (c *Client) attempt(ctx context.Context, method, path string, payload []byte, header http.Header, attempts *int) (*http.Response, error) {
+	bases, pool := c.baseURLs(ctx)
+	httpClient := c.httpClientFor(ctx)
+
+	var lastErr error
+	for _, base := range bases {
+		var bodyReader io.Reader
+		if payload != nil {
+			bodyReader = bytes.NewReader(payload)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, base+path, bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		c.hookRequest(ctx, req)
+
+		*attempts++
+		var attrs []any
+		if c.logger != nil {
+			attrs = requestAttrs(ctx, method, path, *attempts, base)
+			c.logRequest(ctx, req, attrs, payload)
+		}
+
+		start := time.Now()
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("execute request: %w", err)
+			if ctx.Err() != nil || pool == nil || !isConnectionError(err) {
+				c.logFailure(ctx, lastErr, attrs, time.Since(start), false)
+				return nil, lastErr
+			}
+			c.logFailure(ctx, lastErr, attrs, time.Since(start), true)
+			pool.markDown(base, err)
+			if info := callInfoFrom(ctx); info != nil {
+				c.recordRetry(info.Operation, info.Model)
+			}
+			continue
+		}
+		if pool != nil {
+			pool.observe(base, time.Since(start))
+		}
+		c.logResponse(ctx, path, resp, attrs, time.Since(start))
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
 // doRequest performs an HTTP request and returns the decoded response.
 // It marshals the request body to JSON, sets required headers including the API key,
 // executes the HTTP request, and decodes the response body into the specified response type.
@@ -50,28 +170,62 @@ func doRequest[Req any, Resp any](
 	method, path string,
 	body *Req,
 ) (*Resp, error) {
-	url := c.BaseURL + path
+	return doRequestWith(c, ctx, method, path, body, func(r io.Reader) (*Resp, error) {
+		var decoded Resp
+		if err := json.NewDecoder(io.LimitReader(r, maxResponseSize)).Decode(&decoded); err != nil {
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+		return &decoded, nil
+	})
+}
+
+// doRequestWith is doRequest with a custom decoder for the response body,
+// for responses that are not a single JSON value.
+func doRequestWith[Req any, Resp any](
+	c *Client,
+	ctx context.Context,
+	method, path string,
+	body *Req,
+	decode func(io.Reader) (*Resp, error),
+) (result *Resp, err error) {
+	var described any
+	if body != nil {
+		described = body
+	}
+	ctx, span := c.startCall(ctx, method, path, described)
+	ctx = c.withHookParams(ctx, described)
+	var resp *http.Response
+	defer func() {
+		res := CallResult{Err: err}
+		if resp != nil {
+			res.StatusCode = resp.StatusCode
+		}
+		if d, ok := any(result).(resultDescriber); ok && result != nil {
+			d.describeResult(&res)
+		}
+		span.End(res)
+		if err != nil {
+			c.hookError(ctx, described, resp, err)
+		} else {
+			c.hookResponse(ctx, described, resp, result)
+		}
+	}()
 
-	var bodyReader io.Reader
+	var payload []byte
 	if body != nil {
 		jsonData, err := json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("marshal request: %w", err)
 		}
-		bodyReader = bytes.NewReader(jsonData)
+		payload = jsonData
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("CENCORI_API_KEY", c.APIKey)
+	header := c.headers()
+	span.Inject(header)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err = c.send(ctx, method, path, payload, header)
 	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close() //nolint:errcheck // Closing the response body; error can be ignored here.
 
@@ -79,12 +233,5 @@ func doRequest[Req any, Resp any](
 		return nil, handleError(resp)
 	}
 
-	limitedBody := io.LimitReader(resp.Body, maxResponseSize)
-
-	var result Resp
-	if err := json.NewDecoder(limitedBody).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
-	}
-
-	return &result, nil
+	return decode(resp.Body)
 }
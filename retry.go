@@ -0,0 +1,83 @@
+package cencori
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a transient error are
+// retried. The zero value disables retries.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry (default 500ms). It
+	// doubles after every retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries, including waits requested by
+	// a Retry-After header (default 30s).
+	MaxBackoff time.Duration
+	// RetryOn reports whether an attempt should be retried. resp is nil when
+	// err is set. The default retries connection errors, 429 and 5xx
+	// responses other than 501.
+	RetryOn func(resp *http.Response, err error) bool
+}
+
+// WithRetryPolicy retries every request that fails with a transient error
+// according to p. Use WithRequestRetryPolicy to override it for one call.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *ClientOptions) { c.Retry = &p }
+}
+
+func (p *RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if p.RetryOn != nil {
+		return p.RetryOn(resp, err)
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && isConnectionError(err)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// backoff returns the wait before retry number attempt (0-based), honoring
+// a Retry-After header in seconds on resp.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	initial, maxWait := p.InitialBackoff, p.MaxBackoff
+	if initial <= 0 {
+		initial = 500 * time.Millisecond
+	}
+	if maxWait <= 0 {
+		maxWait = 30 * time.Second
+	}
+
+	wait := initial << min(attempt, 20)
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			wait = time.Duration(secs) * time.Second
+		}
+	}
+	return min(wait, maxWait)
+}
+
+// retryPolicy returns the policy for the call in ctx, or nil when retries are disabled.
+func (c *Client) retryPolicy(ctx context.Context) *RetryPolicy {
+	p := c.retry
+	if o := requestOptionsFrom(ctx); o != nil && o.retry != nil {
+		p = o.retry
+	}
+	if p == nil || p.MaxRetries <= 0 {
+		return nil
+	}
+	return p
+}
+
+// discard drains and closes the body of a response that will be retried so
+// the connection can be reused.
+func discard(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize)) //nolint:errcheck // The body is being discarded.
+	resp.Body.Close()                                               //nolint:errcheck // Closing the response body; error can be ignored here.
+}
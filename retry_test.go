@@ -0,0 +1,105 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first n requests with status, then succeeds.
+func flakyServer(n int32, status int, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= n {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":"try again"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-1"})
+	}))
+}
+
+func TestRetryPolicy_RetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := flakyServer(2, http.StatusServiceUnavailable, &calls)
+	defer server.Close()
+
+	rec := &fakeRecorder{}
+	client, _ := NewClient(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}),
+		WithMetrics(rec),
+	)
+
+	resp, err := client.Chat.Create(context.Background(), &ChatParams{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.ID != "chat-1" || calls.Load() != 3 {
+		t.Errorf("expected success on the third attempt, got %q after %d calls", resp.ID, calls.Load())
+	}
+	if len(rec.retries) != 2 {
+		t.Errorf("expected 2 recorded retries, got %v", rec.retries)
+	}
+}
+
+func TestRetryPolicy_PerCallOverride(t *testing.T) {
+	var calls atomic.Int32
+	server := flakyServer(1, http.StatusServiceUnavailable, &calls)
+	defer server.Close()
+
+	client, _ := NewClient(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}),
+	)
+
+	_, err := client.Projects.List(context.Background(), "org", WithRequestRetryPolicy(RetryPolicy{}))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 error without retries, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestRetryPolicy_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := flakyServer(1, http.StatusBadRequest, &calls)
+	defer server.Close()
+
+	client, _ := NewClient(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}),
+	)
+
+	if _, err := client.Projects.List(context.Background(), "org"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		if got := p.backoff(attempt, nil); got != want {
+			t.Errorf("attempt %d: expected %v, got %v", attempt, want, got)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"5"}}}
+	if got := p.backoff(0, resp); got != time.Second {
+		t.Errorf("expected Retry-After to be capped at MaxBackoff, got %v", got)
+	}
+}
@@ -0,0 +1,248 @@
+package cencori
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Embedder creates embeddings. *ChatService satisfies it.
+type Embedder interface {
+	Embeddings(ctx context.Context, params EmbeddingParams, opts ...RequestOption) (*EmbeddingResponse, error)
+}
+
+var _ Embedder = (*ChatService)(nil)
+
+// SemanticCacheConfig configures a SemanticCache.
+type SemanticCacheConfig struct {
+	// Embedder embeds incoming prompts, usually client.Chat.
+	Embedder Embedder
+	// EmbeddingModel is the model used to embed prompts (default "text-embedding-3-small").
+	EmbeddingModel string
+	// Threshold is the minimum cosine similarity for a cache hit (default 0.95).
+	Threshold float64
+	// MaxEntries bounds the number of cached responses; the oldest are evicted first (default 1000).
+	MaxEntries int
+	// TTL is how long a response stays cached. Zero means forever.
+	TTL time.Duration
+	// ShareAcrossModels lets a response from one model answer a prompt sent to another.
+	ShareAcrossModels bool
+	// ShareAcrossSystemPrompts lets a response be reused under a different system prompt.
+	ShareAcrossSystemPrompts bool
+}
+
+type semanticEntry struct {
+	scope     string
+	vector    []float32
+	response  []byte
+	expiresAt time.Time
+}
+
+// SemanticCache answers chat requests whose prompt is a near-duplicate of a
+// previously seen prompt. Install it with Client.Use(cache.Middleware()).
+type SemanticCache struct {
+	cfg     SemanticCacheConfig
+	now     func() time.Time
+	mu      sync.RWMutex
+	entries []*semanticEntry
+}
+
+// NewSemanticCache creates a SemanticCache. cfg.Embedder is required.
+func NewSemanticCache(cfg SemanticCacheConfig) *SemanticCache {
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = "text-embedding-3-small"
+	}
+	if cfg.Threshold <= 0 || cfg.Threshold > 1 {
+		cfg.Threshold = 0.95
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 1000
+	}
+	return &SemanticCache{cfg: cfg, now: time.Now}
+}
+
+// Middleware returns the middleware that consults the cache on Chat.Create.
+func (sc *SemanticCache) Middleware() Middleware {
+	return Middleware{
+		Create: func(next CreateFunc) CreateFunc {
+			return func(ctx context.Context, params *ChatParams) (*ChatResponse, error) {
+				return sc.create(ctx, params, next)
+			}
+		},
+	}
+}
+
+// Len returns the number of cached responses.
+func (sc *SemanticCache) Len() int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return len(sc.entries)
+}
+
+// Clear removes every cached response.
+func (sc *SemanticCache) Clear() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.entries = nil
+}
+
+func (sc *SemanticCache) create(ctx context.Context, params *ChatParams, next CreateFunc) (*ChatResponse, error) {
+	prompt := semanticPrompt(params.Messages)
+	if prompt == "" {
+		return next(ctx, params)
+	}
+
+	emb, err := sc.cfg.Embedder.Embeddings(forInternalRequest(ctx), EmbeddingParams{Input: EmbeddingText(prompt), Model: sc.cfg.EmbeddingModel})
+	if err != nil || len(emb.Data) == 0 {
+		// The cache is an optimization; never fail a request because of it.
+		return next(ctx, params)
+	}
+	vector := emb.Data[0].Embedding
+	scope, err := sc.scope(ctx, params)
+	if err != nil {
+		return next(ctx, params)
+	}
+
+	if data, ok := sc.lookup(scope, vector); ok {
+		var resp ChatResponse
+		if err := json.Unmarshal(data, &resp); err == nil {
+			resp.CacheHit = true
+			return &resp, nil
+		}
+	}
+
+	resp, err := next(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(resp); err == nil {
+		sc.store(scope, vector, data)
+	}
+	return resp, nil
+}
+
+func (sc *SemanticCache) lookup(scope string, vector []float32) ([]byte, bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	now := sc.now()
+	var best *semanticEntry
+	bestScore := sc.cfg.Threshold
+	for _, e := range sc.entries {
+		if e.scope != scope || (!e.expiresAt.IsZero() && now.After(e.expiresAt)) {
+			continue
+		}
+		if score := cosineSimilarity(vector, e.vector); score >= bestScore {
+			best, bestScore = e, score
+		}
+	}
+	if best == nil {
+		return nil, false
+	}
+	return best.response, true
+}
+
+func (sc *SemanticCache) store(scope string, vector []float32, response []byte) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	entry := &semanticEntry{scope: scope, vector: vector, response: response}
+	if sc.cfg.TTL > 0 {
+		entry.expiresAt = sc.now().Add(sc.cfg.TTL)
+	}
+
+	// Drop expired entries before evicting live ones.
+	now := sc.now()
+	live := sc.entries[:0]
+	for _, e := range sc.entries {
+		if e.expiresAt.IsZero() || now.Before(e.expiresAt) {
+			live = append(live, e)
+		}
+	}
+	sc.entries = append(live, entry)
+	if over := len(sc.entries) - sc.cfg.MaxEntries; over > 0 {
+		sc.entries = sc.entries[over:]
+	}
+}
+
+// scope returns the partition a request may share cached responses with:
+// requests from the same API key to the same gateway with the same sampling
+// parameters and, unless configured otherwise, model and system prompt.
+func (sc *SemanticCache) scope(ctx context.Context, params *ChatParams) (string, error) {
+	var tenant string
+	if c := clientFrom(ctx); c != nil {
+		s, err := c.cacheScope(ctx)
+		if err != nil {
+			return "", err
+		}
+		tenant = s
+	} else if o := requestOptionsFrom(ctx); o != nil {
+		tenant = o.apiKey + "\x00" + o.baseURL
+	}
+	sum := sha256.Sum256([]byte(tenant))
+	sampling, err := json.Marshal(struct {
+		Temperature *float64 `json:"t"`
+		TopP        *float64 `json:"p"`
+		MaxTokens   *int     `json:"m"`
+		User        *string  `json:"u"`
+	}{params.Temperature, params.TopP, params.MaxTokens, params.User})
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{hex.EncodeToString(sum[:]), string(sampling)}
+	if !sc.cfg.ShareAcrossModels {
+		parts = append(parts, params.Model)
+	}
+	if !sc.cfg.ShareAcrossSystemPrompts {
+		h := sha256.New()
+		for _, m := range params.Messages {
+			if m.Role == "system" {
+				h.Write([]byte(m.Content))
+				h.Write([]byte{0})
+			}
+		}
+		parts = append(parts, hex.EncodeToString(h.Sum(nil)))
+	}
+	return strings.Join(parts, "|"), nil
+}
+
+// semanticPrompt renders the non-system messages of a conversation as the
+// text that is embedded and compared.
+func semanticPrompt(messages []Message) string {
+	var b strings.Builder
+	for _, m := range messages {
+		if m.Role == "system" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(m.Role)
+		b.WriteString(": ")
+		b.WriteString(m.Content)
+	}
+	return b.String()
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,153 @@
+package cencori
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeEmbedder maps prompts to fixed vectors: prompts mentioning "weather"
+// point one way and everything else points another.
+type fakeEmbedder struct {
+	calls atomic.Int32
+}
+
+func (f *fakeEmbedder) Embeddings(_ context.Context, params EmbeddingParams, _ ...RequestOption) (*EmbeddingResponse, error) {
+	f.calls.Add(1)
+	vec := []float32{0, 1}
+	if texts := params.Input.Texts(); len(texts) == 1 && strings.Contains(texts[0], "weather") {
+		vec = []float32{1, 0.05}
+	}
+	return &EmbeddingResponse{Data: []EmbeddingData{{Embedding: vec}}}, nil
+}
+
+func TestSemanticCache_NearDuplicateHit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(ChatResponse{ID: "chat-123"})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithAPIKey("test-key"), WithBaseURL(server.URL))
+	embedder := &fakeEmbedder{}
+	cache := NewSemanticCache(SemanticCacheConfig{Embedder: embedder, Threshold: 0.9})
+	client.Use(cache.Middleware())
+
+	ask := func(model, system, prompt string) *ChatResponse {
+		t.Helper()
+		resp, err := client.Chat.Create(context.Background(), &ChatParams{
+			Model: model,
+			Messages: []Message{
+				{Role: "system", Content: system},
+				{Role: "user", Content: prompt},
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return resp
+	}
+
+	if resp := ask("gpt-4o", "be brief", "What's the weather?"); resp.CacheHit {
+		t.Error("first request should miss")
+	}
+	if resp := ask("gpt-4o", "be brief", "How is the weather today?"); !resp.CacheHit || resp.ID != "chat-123" {
+		t.Errorf("near-duplicate should hit, got %+v", resp)
+	}
+	if resp := ask("gpt-4o", "be brief", "Tell me a joke"); resp.CacheHit {
+		t.Error("unrelated prompt should miss")
+	}
+	if resp := ask("claude-3-sonnet", "be brief", "What's the weather?"); resp.CacheHit {
+		t.Error("different model should miss by default")
+	}
+	if resp := ask("gpt-4o", "be verbose", "What's the weather?"); resp.CacheHit {
+		t.Error("different system prompt should miss by default")
+	}
+
+	if calls.Load() != 4 {
+		t.Errorf("expected 4 upstream calls, got %d", calls.Load())
+	}
+	if embedder.calls.Load() != 5 {
+		t.Errorf("expected 5 embedding calls, got %d", embedder.calls.Load())
+	}
+	if cache.Len() != 4 {
+		t.Errorf("expected 4 cached entries, got %d", cache.Len())
+	}
+}
+
+func TestSemanticCache_SharedScope(t *testing.T) {
+	var calls atomic.Int32
+	next := func(context.Context, *ChatParams) (*ChatResponse, error) {
+		calls.Add(1)
+		return &ChatResponse{ID: "chat-123"}, nil
+	}
+
+	cache := NewSemanticCache(SemanticCacheConfig{
+		Embedder:          &fakeEmbedder{},
+		ShareAcrossModels: true,
+	})
+	create := cache.Middleware().Create(next)
+
+	for _, model := range []string{"gpt-4o", "gemini-pro"} {
+		_, err := create(context.Background(), &ChatParams{
+			Model:    model,
+			Messages: []Message{{Role: "user", Content: "weather?"}},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a shared hit across models, got %d upstream calls", calls.Load())
+	}
+}
+
+func TestSemanticCache_ScopedByKeyAndSampling(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(ChatResponse{ID: r.Header.Get("CENCORI_API_KEY")})
+	}))
+	defer server.Close()
+
+	cache := NewSemanticCache(SemanticCacheConfig{Embedder: &fakeEmbedder{}})
+	tenantA, _ := NewClient(WithAPIKey("key-a"), WithBaseURL(server.URL))
+	tenantB, _ := NewClient(WithAPIKey("key-b"), WithBaseURL(server.URL))
+	tenantA.Use(cache.Middleware())
+	tenantB.Use(cache.Middleware())
+
+	ask := func(client *Client, temperature float64, opts ...RequestOption) *ChatResponse {
+		t.Helper()
+		resp, err := client.Chat.Create(context.Background(), &ChatParams{
+			Model:       "gpt-4o",
+			Temperature: &temperature,
+			Messages:    []Message{{Role: "user", Content: "weather?"}},
+		}, opts...)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return resp
+	}
+
+	ask(tenantA, 0)
+	if resp := ask(tenantB, 0); resp.CacheHit || resp.ID != "key-b" {
+		t.Errorf("expected another key to miss, got %+v", resp)
+	}
+	if resp := ask(tenantA, 0, WithRequestAPIKey("key-c")); resp.CacheHit {
+		t.Error("expected a per-call API key to miss")
+	}
+	if resp := ask(tenantA, 1); resp.CacheHit {
+		t.Error("expected a different temperature to miss")
+	}
+	if resp := ask(tenantA, 0); !resp.CacheHit || resp.ID != "key-a" {
+		t.Errorf("expected the same key and parameters to hit, got %+v", resp)
+	}
+	if calls.Load() != 4 {
+		t.Errorf("expected 4 upstream calls, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,158 @@
+package cencori
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Tracer instruments API calls, for example with OpenTelemetry spans (see the
+// otelcencori package). Implementations must be safe for concurrent use.
+type Tracer interface {
+	// Start is called before a request is sent. The returned context is used
+	// for the request.
+	Start(ctx context.Context, call CallInfo) (context.Context, CallSpan)
+}
+
+// CallSpan tracks a single API call started by a Tracer.
+type CallSpan interface {
+	// Inject adds trace context headers to the outgoing request.
+	Inject(header http.Header)
+	// FirstToken is called when the first streamed content arrives.
+	FirstToken()
+	// End is called once when the call completes. For streams that is when
+	// the stream ends, not when Stream returns.
+	End(result CallResult)
+}
+
+// CallInfo describes an API call as it starts.
+type CallInfo struct {
+	// Operation is "chat", "embeddings" or "moderation" for model calls and
+	// empty for management calls.
+	Operation string
+	Method    string
+	Path      string
+	// Model is the requested model, if any.
+	Model  string
+	Stream bool
+}
+
+// CallResult describes the outcome of an API call.
+type CallResult struct {
+	// StatusCode is the HTTP status, or zero if no response was received.
+	StatusCode    int
+	ResponseID    string
+	ResponseModel string
+	Usage         Usage
+	// FinishReasons holds the finish reason of each choice.
+	FinishReasons []string
+	Err           error
+}
+
+// WithTracer instruments every API call with t.
+func WithTracer(t Tracer) Option {
+	return func(c *ClientOptions) { c.Tracer = t }
+}
+
+type noopSpan struct{}
+
+func (noopSpan) Inject(http.Header) {}
+func (noopSpan) FirstToken()        {}
+func (noopSpan) End(CallResult)     {}
+
+// startCall starts instrumentation for a call described by body, which may be nil.
+// The call is recorded in the returned context for request logging.
+func (c *Client) startCall(ctx context.Context, method, path string, body any) (context.Context, CallSpan) {
+	if c.tracer == nil && c.logger == nil && c.metrics == nil {
+		return ctx, noopSpan{}
+	}
+	info := CallInfo{Method: method, Path: path}
+	if d, ok := body.(callDescriber); ok {
+		d.describeCall(&info)
+	}
+	ctx = context.WithValue(ctx, callInfoKey{}, &info)
+
+	var span CallSpan = noopSpan{}
+	if c.tracer != nil {
+		ctx, span = c.tracer.Start(ctx, info)
+	}
+	if c.metrics != nil && info.Operation != "" {
+		span = &metricsSpan{next: span, client: c, info: info, start: time.Now()}
+	}
+	return ctx, span
+}
+
+type callDescriber interface {
+	describeCall(info *CallInfo)
+}
+
+func (p *ChatParams) describeCall(info *CallInfo) {
+	info.Operation, info.Model, info.Stream = "chat", p.Model, p.Stream
+}
+
+func (p *EmbeddingParams) describeCall(info *CallInfo) {
+	info.Operation, info.Model = "embeddings", p.Model
+}
+
+func (p *ModerationParams) describeCall(info *CallInfo) {
+	info.Operation, info.Model = "moderation", p.Model
+}
+
+type resultDescriber interface {
+	describeResult(result *CallResult)
+}
+
+func (r *ChatResponse) describeResult(result *CallResult) {
+	result.ResponseID, result.ResponseModel, result.Usage = r.ID, r.Model, r.Usage
+	for _, c := range r.Choices {
+		result.FinishReasons = append(result.FinishReasons, c.FinishReason)
+	}
+}
+
+func (r *EmbeddingResponse) describeResult(result *CallResult) {
+	result.ResponseModel = r.Model
+	result.Usage = Usage{PromptTokens: r.Usage.TotalTokens, TotalTokens: r.Usage.TotalTokens}
+}
+
+func (r *ModerationResponse) describeResult(result *CallResult) {
+	result.ResponseID, result.ResponseModel = r.ID, r.Model
+}
+
+// streamResult accumulates a CallResult from stream chunks.
+type streamResult struct {
+	result  CallResult
+	started bool
+	reasons map[int]string
+}
+
+func (s *streamResult) observe(span CallSpan, chunk *StreamChunk) {
+	if chunk.ID != "" {
+		s.result.ResponseID = chunk.ID
+	}
+	if chunk.Model != "" {
+		s.result.ResponseModel = chunk.Model
+	}
+	for _, c := range chunk.Choices {
+		if !s.started && c.Delta.Content != "" {
+			s.started = true
+			span.FirstToken()
+		}
+		if c.FinishReason != nil {
+			if s.reasons == nil {
+				s.reasons = make(map[int]string)
+			}
+			s.reasons[c.Index] = *c.FinishReason
+		}
+	}
+}
+
+func (s *streamResult) finish(err error) CallResult {
+	s.result.StatusCode = http.StatusOK
+	s.result.Err = err
+	for _, i := range slices.Sorted(maps.Keys(s.reasons)) {
+		s.result.FinishReasons = append(s.result.FinishReasons, s.reasons[i])
+	}
+	return s.result
+}
@@ -0,0 +1,232 @@
+package vectorstore
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+)
+
+// HNSWConfig tunes the approximate HNSW index. Zero values use defaults.
+type HNSWConfig struct {
+	// M is the number of neighbours kept per node on upper layers (default 16).
+	// Layer 0 keeps 2*M.
+	M int
+	// EfConstruction is the candidate list size while inserting (default 200).
+	EfConstruction int
+	// EfSearch is the candidate list size while searching (default 64).
+	// Larger values trade speed for recall.
+	EfSearch int
+	// Seed makes level assignment reproducible.
+	Seed uint64
+}
+
+func (c *HNSWConfig) setDefaults() {
+	if c.M <= 0 {
+		c.M = 16
+	}
+	if c.EfConstruction <= 0 {
+		c.EfConstruction = 200
+	}
+	if c.EfSearch <= 0 {
+		c.EfSearch = 64
+	}
+}
+
+type hnswNode struct {
+	level   int
+	friends [][]int
+}
+
+// hnsw is a Hierarchical Navigable Small World graph over the store's
+// vectors. Node ids are the store's internal record positions. Deleted
+// nodes stay in the graph to keep it connected but are never returned.
+type hnsw struct {
+	cfg       HNSWConfig
+	levelMult float64
+	rng       *rand.Rand
+	nodes     map[int]*hnswNode
+	entry     int
+	maxLevel  int
+	vector    func(id int) []float32
+	sim       func(a, b []float32) float64
+	deleted   func(id int) bool
+}
+
+func newHNSW(cfg HNSWConfig, vector func(int) []float32, sim func(a, b []float32) float64, deleted func(int) bool) *hnsw {
+	cfg.setDefaults()
+	return &hnsw{
+		cfg:       cfg,
+		levelMult: 1 / math.Log(float64(cfg.M)),
+		rng:       rand.New(rand.NewPCG(cfg.Seed, cfg.Seed^0x9e3779b97f4a7c15)), //nolint:gosec // Level assignment does not need a CSPRNG.
+		nodes:     make(map[int]*hnswNode),
+		entry:     -1,
+		vector:    vector,
+		sim:       sim,
+		deleted:   deleted,
+	}
+}
+
+func (h *hnsw) maxConn(level int) int {
+	if level == 0 {
+		return 2 * h.cfg.M
+	}
+	return h.cfg.M
+}
+
+func (h *hnsw) insert(id int) {
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+	node := &hnswNode{level: level, friends: make([][]int, level+1)}
+	h.nodes[id] = node
+
+	if h.entry < 0 {
+		h.entry, h.maxLevel = id, level
+		return
+	}
+
+	q := h.vector(id)
+	ep := h.entry
+	for l := h.maxLevel; l > level; l-- {
+		ep = h.greedy(q, ep, l)
+	}
+
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(q, ep, h.cfg.EfConstruction, l, false)
+		neighbours := candidates
+		if len(neighbours) > h.cfg.M {
+			neighbours = neighbours[:h.cfg.M]
+		}
+
+		node.friends[l] = make([]int, 0, len(neighbours))
+		for _, nb := range neighbours {
+			node.friends[l] = append(node.friends[l], nb.id)
+			h.link(nb.id, id, l)
+		}
+		ep = candidates[0].id
+	}
+
+	if level > h.maxLevel {
+		h.entry, h.maxLevel = id, level
+	}
+}
+
+// link adds to as a neighbour of from on layer l, pruning from's neighbour
+// list to the closest maxConn(l) nodes.
+func (h *hnsw) link(from, to, l int) {
+	node := h.nodes[from]
+	node.friends[l] = append(node.friends[l], to)
+	if len(node.friends[l]) <= h.maxConn(l) {
+		return
+	}
+
+	base := h.vector(from)
+	ranked := make([]scoredID, len(node.friends[l]))
+	for i, f := range node.friends[l] {
+		ranked[i] = scoredID{id: f, score: h.sim(base, h.vector(f))}
+	}
+	sortDesc(ranked)
+
+	node.friends[l] = node.friends[l][:0]
+	for _, s := range ranked[:h.maxConn(l)] {
+		node.friends[l] = append(node.friends[l], s.id)
+	}
+}
+
+// greedy walks layer l towards q and returns the closest node found.
+func (h *hnsw) greedy(q []float32, ep, l int) int {
+	best := h.sim(q, h.vector(ep))
+	for changed := true; changed; {
+		changed = false
+		for _, f := range h.nodes[ep].friends[l] {
+			if s := h.sim(q, h.vector(f)); s > best {
+				best, ep, changed = s, f, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer returns up to ef nodes close to q on layer l, closest first.
+// With liveOnly set, deleted nodes are traversed but not returned.
+func (h *hnsw) searchLayer(q []float32, ep, ef, l int, liveOnly bool) []scoredID {
+	visited := map[int]bool{ep: true}
+	first := scoredID{id: ep, score: h.sim(q, h.vector(ep))}
+
+	candidates := &maxHeap{first}
+	results := &minHeap{}
+	if !liveOnly || !h.deleted(ep) {
+		heap.Push(results, first)
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(scoredID) //nolint:errcheck // The heap only holds scoredID values.
+		if results.Len() >= ef && c.score < (*results)[0].score {
+			break
+		}
+		for _, f := range h.nodes[c.id].friends[l] {
+			if visited[f] {
+				continue
+			}
+			visited[f] = true
+
+			s := scoredID{id: f, score: h.sim(q, h.vector(f))}
+			if results.Len() < ef || s.score > (*results)[0].score {
+				heap.Push(candidates, s)
+				if liveOnly && h.deleted(f) {
+					continue
+				}
+				heap.Push(results, s)
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	out := make([]scoredID, results.Len())
+	copy(out, *results)
+	sortDesc(out)
+	return out
+}
+
+// search returns up to ef live nodes close to q, closest first.
+func (h *hnsw) search(q []float32, ef int) []scoredID {
+	if h.entry < 0 {
+		return nil
+	}
+	ep := h.entry
+	for l := h.maxLevel; l > 0; l-- {
+		ep = h.greedy(q, ep, l)
+	}
+	return h.searchLayer(q, ep, ef, 0, true)
+}
+
+type scoredID struct {
+	id    int
+	score float64
+}
+
+type minHeap []scoredID
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(scoredID)) } //nolint:errcheck // Only scoredID is pushed.
+func (h *minHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+type maxHeap []scoredID
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].score > h[j].score }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(scoredID)) } //nolint:errcheck // Only scoredID is pushed.
+func (h *maxHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
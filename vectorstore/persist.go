@@ -0,0 +1,82 @@
+package vectorstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type snapshot struct {
+	Version int        `json:"version"`
+	Metric  Metric     `json:"metric"`
+	Index   IndexKind  `json:"index"`
+	HNSW    HNSWConfig `json:"hnsw"`
+	Records []Record   `json:"records"`
+}
+
+// Save writes the store's options and live records to w as JSON.
+func (s *Store) Save(w io.Writer) error {
+	s.mu.RLock()
+	snap := snapshot{Version: 1, Metric: s.opts.Metric, Index: s.opts.Index, HNSW: s.opts.HNSW}
+	for i, r := range s.records {
+		if !s.deleted[i] {
+			snap.Records = append(snap.Records, r)
+		}
+	}
+	s.mu.RUnlock()
+
+	if err := json.NewEncoder(w).Encode(snap); err != nil {
+		return fmt.Errorf("vectorstore: save: %w", err)
+	}
+	return nil
+}
+
+// Load reads a store written by Save. Approximate indexes are rebuilt.
+func Load(r io.Reader) (*Store, error) {
+	var snap snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("vectorstore: load: %w", err)
+	}
+	if snap.Version != 1 {
+		return nil, fmt.Errorf("vectorstore: load: unsupported version %d", snap.Version)
+	}
+
+	s := New(Options{Metric: snap.Metric, Index: snap.Index, HNSW: snap.HNSW})
+	if err := s.Add(snap.Records...); err != nil {
+		return nil, fmt.Errorf("vectorstore: load: %w", err)
+	}
+	return s, nil
+}
+
+// SaveFile writes the store to path atomically.
+func (s *Store) SaveFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("vectorstore: save: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // The temp file is gone after a successful rename.
+
+	if err := s.Save(tmp); err != nil {
+		tmp.Close() //nolint:errcheck // The save error is more useful.
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("vectorstore: save: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("vectorstore: save: %w", err)
+	}
+	return nil
+}
+
+// LoadFile reads a store written by SaveFile.
+func LoadFile(path string) (*Store, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("vectorstore: load: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // Read-only file; close errors are not actionable.
+	return Load(f)
+}
@@ -0,0 +1,326 @@
+// Package vectorstore is an in-memory vector index for embeddings produced by
+// cencori's ChatService.Embeddings. It supports cosine, dot-product and L2
+// search with top-k and metadata filters, exact or HNSW approximate search,
+// and persistence to disk.
+package vectorstore
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/cencori/cencori-go"
+)
+
+// Metric is the similarity measure used for search.
+type Metric int
+
+const (
+	// Cosine ranks by cosine similarity.
+	Cosine Metric = iota
+	// Dot ranks by dot product.
+	Dot
+	// L2 ranks by Euclidean distance, nearest first.
+	L2
+)
+
+func (m Metric) String() string {
+	switch m {
+	case Cosine:
+		return "cosine"
+	case Dot:
+		return "dot"
+	case L2:
+		return "l2"
+	default:
+		return fmt.Sprintf("Metric(%d)", int(m))
+	}
+}
+
+// IndexKind selects the search algorithm.
+type IndexKind int
+
+const (
+	// Flat compares the query against every record. Results are exact.
+	Flat IndexKind = iota
+	// HNSW uses an approximate Hierarchical Navigable Small World graph,
+	// which is much faster for large corpora at a small cost in recall.
+	HNSW
+)
+
+// Options configures a Store.
+type Options struct {
+	Metric Metric
+	Index  IndexKind
+	// HNSW tunes the graph when Index is HNSW.
+	HNSW HNSWConfig
+}
+
+// Record is a stored vector with its content and metadata.
+type Record struct {
+	ID       string            `json:"id"`
+	Vector   []float32         `json:"vector"`
+	Content  string            `json:"content,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Document describes the input that produced an embedding, for use with AddEmbeddings.
+type Document struct {
+	ID       string
+	Content  string
+	Metadata map[string]string
+}
+
+// Result is a search hit. Score is higher for closer matches; for L2 it is
+// the negated distance.
+type Result struct {
+	Record
+	Score float64
+}
+
+// Filter reports whether a record may be returned from a search.
+type Filter func(Record) bool
+
+// MatchMetadata returns a Filter that keeps records whose metadata contains
+// every key/value pair in want.
+func MatchMetadata(want map[string]string) Filter {
+	return func(r Record) bool {
+		for k, v := range want {
+			if r.Metadata[k] != v {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+var (
+	// ErrDimensionMismatch is returned when a vector's length differs from the store's.
+	ErrDimensionMismatch = errors.New("vectorstore: dimension mismatch")
+	// ErrMissingID is returned when a record has no ID.
+	ErrMissingID = errors.New("vectorstore: record has no ID")
+)
+
+// Store is an in-memory vector index. It is safe for concurrent use.
+type Store struct {
+	opts Options
+
+	mu      sync.RWMutex
+	dim     int
+	records []Record    // by internal id; deleted slots are kept for the graph
+	deleted []bool      // by internal id
+	vectors [][]float32 // by internal id; normalized for Cosine
+	ids     map[string]int
+	graph   *hnsw
+}
+
+// New creates an empty Store.
+func New(opts Options) *Store {
+	s := &Store{opts: opts, ids: make(map[string]int)}
+	if opts.Index == HNSW {
+		s.graph = newHNSW(opts.HNSW, func(id int) []float32 { return s.vectors[id] }, s.similarity,
+			func(id int) bool { return s.deleted[id] })
+	}
+	return s
+}
+
+// Len returns the number of records in the store.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.ids)
+}
+
+// Dimension returns the vector length of the store, or 0 while it is empty.
+func (s *Store) Dimension() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dim
+}
+
+// Add inserts records, replacing any existing record with the same ID.
+func (s *Store) Add(records ...Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dim := s.dim
+	for _, r := range records {
+		if r.ID == "" {
+			return ErrMissingID
+		}
+		if dim == 0 {
+			dim = len(r.Vector)
+		}
+		if len(r.Vector) != dim || dim == 0 {
+			return fmt.Errorf("%w: record %q has %d dimensions, want %d", ErrDimensionMismatch, r.ID, len(r.Vector), dim)
+		}
+	}
+	s.dim = dim
+
+	for _, r := range records {
+		if old, ok := s.ids[r.ID]; ok {
+			s.deleted[old] = true
+		}
+
+		id := len(s.records)
+		s.records = append(s.records, r)
+		s.deleted = append(s.deleted, false)
+		s.vectors = append(s.vectors, s.prepare(r.Vector))
+		s.ids[r.ID] = id
+		if s.graph != nil {
+			s.graph.insert(id)
+		}
+	}
+	return nil
+}
+
+// AddEmbeddings stores the embeddings of an EmbeddingResponse. docs[i]
+// describes the input at position i, matched through EmbeddingData.Index.
+func (s *Store) AddEmbeddings(resp *cencori.EmbeddingResponse, docs []Document) error {
+	records := make([]Record, 0, len(resp.Data))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(docs) {
+			return fmt.Errorf("vectorstore: embedding index %d has no matching document", d.Index)
+		}
+		doc := docs[d.Index]
+		records = append(records, Record{
+			ID:       doc.ID,
+			Vector:   d.Embedding,
+			Content:  doc.Content,
+			Metadata: doc.Metadata,
+		})
+	}
+	return s.Add(records...)
+}
+
+// Get returns the record with the given ID.
+func (s *Store) Get(id string) (Record, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i, ok := s.ids[id]
+	if !ok {
+		return Record{}, false
+	}
+	return s.records[i], true
+}
+
+// Delete removes the record with the given ID and reports whether it existed.
+func (s *Store) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.ids[id]
+	if !ok {
+		return false
+	}
+	s.deleted[i] = true
+	delete(s.ids, id)
+	return true
+}
+
+// Search returns the k records closest to query that pass filter, closest
+// first. filter may be nil.
+func (s *Store) Search(query []float32, k int, filter Filter) ([]Result, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if k <= 0 || len(s.ids) == 0 {
+		return nil, nil
+	}
+	if len(query) != s.dim {
+		return nil, fmt.Errorf("%w: query has %d dimensions, want %d", ErrDimensionMismatch, len(query), s.dim)
+	}
+	q := s.prepare(query)
+
+	if s.graph != nil {
+		ef := max(s.graph.cfg.EfSearch, k)
+		if filter != nil {
+			ef = max(ef, 4*k)
+		}
+		if hits := s.collect(s.graph.search(q, ef), k, filter); len(hits) == k || ef >= len(s.records) {
+			return hits, nil
+		}
+		// Too few hits survived the filter; fall back to an exact scan.
+	}
+
+	all := make([]scoredID, 0, len(s.ids))
+	for _, id := range s.ids {
+		all = append(all, scoredID{id: id, score: s.similarity(q, s.vectors[id])})
+	}
+	sortDesc(all)
+	return s.collect(all, k, filter), nil
+}
+
+// collect converts ranked candidates into at most k results, skipping deleted
+// and filtered records. Callers hold s.mu.
+func (s *Store) collect(ranked []scoredID, k int, filter Filter) []Result {
+	out := make([]Result, 0, k)
+	for _, c := range ranked {
+		if s.deleted[c.id] {
+			continue
+		}
+		r := s.records[c.id]
+		if filter != nil && !filter(r) {
+			continue
+		}
+		out = append(out, Result{Record: r, Score: c.score})
+		if len(out) == k {
+			break
+		}
+	}
+	return out
+}
+
+// prepare returns a copy of the vector as stored in the index, normalized
+// to unit length for Cosine so that similarity reduces to a dot product.
+func (s *Store) prepare(v []float32) []float32 {
+	if s.opts.Metric != Cosine {
+		return slices.Clone(v)
+	}
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if norm == 0 {
+		return out
+	}
+	inv := 1 / math.Sqrt(norm)
+	for i, x := range v {
+		out[i] = float32(float64(x) * inv)
+	}
+	return out
+}
+
+func (s *Store) similarity(a, b []float32) float64 {
+	var sum float64
+	switch s.opts.Metric {
+	case L2:
+		for i := range a {
+			d := float64(a[i]) - float64(b[i])
+			sum += d * d
+		}
+		return -math.Sqrt(sum)
+	case Cosine, Dot:
+		for i := range a {
+			sum += float64(a[i]) * float64(b[i])
+		}
+	}
+	return sum
+}
+
+func sortDesc(s []scoredID) {
+	slices.SortFunc(s, func(a, b scoredID) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		default:
+			return a.id - b.id
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package vectorstore
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/cencori/cencori-go"
+)
+
+func testRecords() []Record {
+	return []Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]string{"lang": "go"}},
+		{ID: "b", Vector: []float32{0.9, 0.1}, Metadata: map[string]string{"lang": "python"}},
+		{ID: "c", Vector: []float32{0, 3}, Metadata: map[string]string{"lang": "go"}},
+	}
+}
+
+func ids(results []Result) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.ID
+	}
+	return out
+}
+
+func TestStore_Metrics(t *testing.T) {
+	tests := []struct {
+		metric Metric
+		query  []float32
+		want   []string
+	}{
+		{Cosine, []float32{1, 0}, []string{"a", "b", "c"}},
+		{Dot, []float32{0, 1}, []string{"c", "b", "a"}},
+		{L2, []float32{0.9, 0.2}, []string{"b", "a", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric.String(), func(t *testing.T) {
+			s := New(Options{Metric: tt.metric})
+			if err := s.Add(testRecords()...); err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+
+			results, err := s.Search(tt.query, 3, nil)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if got := ids(results); len(got) != 3 || got[0] != tt.want[0] || got[1] != tt.want[1] || got[2] != tt.want[2] {
+				t.Errorf("Search = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_FilterDeleteAndReplace(t *testing.T) {
+	s := New(Options{})
+	if err := s.Add(testRecords()...); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	results, _ := s.Search([]float32{1, 0}, 2, MatchMetadata(map[string]string{"lang": "go"}))
+	if got := ids(results); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("filtered Search = %v, want [a c]", got)
+	}
+
+	if !s.Delete("a") || s.Delete("a") {
+		t.Error("Delete should succeed once")
+	}
+	if err := s.Add(Record{ID: "b", Vector: []float32{0, 1}}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	results, _ = s.Search([]float32{1, 0}, 3, nil)
+	if got := ids(results); len(got) != 2 {
+		t.Errorf("Search after delete/replace = %v, want 2 results", got)
+	}
+	if r, _ := s.Get("b"); r.Vector[1] != 1 {
+		t.Errorf("expected b to be replaced, got %v", r.Vector)
+	}
+	if s.Len() != 2 {
+		t.Errorf("Len = %d, want 2", s.Len())
+	}
+
+	if err := s.Add(Record{ID: "d", Vector: []float32{1, 2, 3}}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("expected ErrDimensionMismatch, got %v", err)
+	}
+}
+
+func TestStore_AddEmbeddings(t *testing.T) {
+	resp := &cencori.EmbeddingResponse{
+		Data: []cencori.EmbeddingData{
+			{Embedding: []float32{0, 1}, Index: 1},
+			{Embedding: []float32{1, 0}, Index: 0},
+		},
+	}
+	docs := []Document{
+		{ID: "doc-0", Content: "first"},
+		{ID: "doc-1", Content: "second"},
+	}
+
+	s := New(Options{})
+	if err := s.AddEmbeddings(resp, docs); err != nil {
+		t.Fatalf("AddEmbeddings failed: %v", err)
+	}
+
+	results, _ := s.Search([]float32{1, 0}, 1, nil)
+	if len(results) != 1 || results[0].ID != "doc-0" || results[0].Content != "first" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
+
+func TestStore_SaveLoad(t *testing.T) {
+	s := New(Options{Metric: L2, Index: HNSW})
+	s.Add(testRecords()...)
+	s.Delete("c")
+
+	path := filepath.Join(t.TempDir(), "store.json")
+	if err := s.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	if loaded.Len() != 2 || loaded.opts.Metric != L2 || loaded.opts.Index != HNSW {
+		t.Errorf("loaded store mismatch: len=%d opts=%+v", loaded.Len(), loaded.opts)
+	}
+	if r, ok := loaded.Get("b"); !ok || r.Metadata["lang"] != "python" {
+		t.Errorf("Get(b) = %+v, %v", r, ok)
+	}
+
+	if _, err := Load(bytes.NewBufferString(`{"version":2}`)); err == nil {
+		t.Error("expected error for unsupported version")
+	}
+}
+
+func TestStore_HNSWRecall(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	const n, dim, k = 2000, 32, 10
+
+	flat := New(Options{})
+	approx := New(Options{Index: HNSW, HNSW: HNSWConfig{Seed: 42}})
+	for i := range n {
+		v := make([]float32, dim)
+		for j := range v {
+			v[j] = rng.Float32()*2 - 1
+		}
+		r := Record{ID: strconv.Itoa(i), Vector: v}
+		flat.Add(r)
+		approx.Add(r)
+	}
+
+	var hits, total int
+	for range 20 {
+		q := make([]float32, dim)
+		for j := range q {
+			q[j] = rng.Float32()*2 - 1
+		}
+		exact, _ := flat.Search(q, k, nil)
+		found, _ := approx.Search(q, k, nil)
+
+		want := make(map[string]bool)
+		for _, r := range exact {
+			want[r.ID] = true
+		}
+		for _, r := range found {
+			if want[r.ID] {
+				hits++
+			}
+		}
+		total += k
+	}
+
+	if recall := float64(hits) / float64(total); recall < 0.9 {
+		t.Errorf("HNSW recall = %.2f, want >= 0.90", recall)
+	}
+}
+
+func TestStore_HNSWDelete(t *testing.T) {
+	const n, k = 500, 10
+	s := New(Options{Metric: L2, Index: HNSW, HNSW: HNSWConfig{EfSearch: 16, Seed: 42}})
+	for i := range n {
+		if err := s.Add(Record{ID: strconv.Itoa(i), Vector: []float32{float32(i), 1}}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	for i := range 100 {
+		s.Delete(strconv.Itoa(i))
+	}
+
+	// Search falls back to an exact scan when the graph comes up short, so
+	// check the graph directly.
+	results := s.collect(s.graph.search([]float32{0, 1}, k), k, nil)
+	if got := ids(results); len(got) != k || got[0] != "100" {
+		t.Errorf("graph search after deleting the nearest records = %v, want %d live results from 100", got, k)
+	}
+}
+
+func TestStore_CopiesVectors(t *testing.T) {
+	for _, m := range []Metric{Dot, L2} {
+		s := New(Options{Metric: m})
+		v := []float32{1, 0}
+		if err := s.Add(Record{ID: "a", Vector: v}, Record{ID: "b", Vector: []float32{0.5, 0.5}}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		v[0], v[1] = 0, 1
+
+		results, _ := s.Search([]float32{1, 0}, 1, nil)
+		if got := ids(results); len(got) != 1 || got[0] != "a" {
+			t.Errorf("%s: Search = %v, want [a] despite the caller reusing its slice", m, got)
+		}
+	}
+}